@@ -2,12 +2,14 @@ package anthropic
 
 import (
 	"commi/internal/clients/common"
+	"commi/internal/core"
 	"commi/internal/utils"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"sync"
 
 	"github.com/rs/zerolog/log"
 )
@@ -30,6 +32,9 @@ type AnthropicClient struct {
 	model  string
 	client *http.Client
 	config common.ClientConfig
+
+	mu    sync.Mutex
+	usage core.Usage
 }
 
 func NewAnthropicClient(key string) *AnthropicClient {
@@ -46,18 +51,34 @@ func NewAnthropicClient(key string) *AnthropicClient {
 		model:  defaultModel,
 		client: common.NewHTTPClient(clientConfig),
 		config: clientConfig,
+		usage:  core.Usage{Provider: "anthropic", Model: defaultModel},
 	}
 }
 
+// Usage returns the tokens used by all requests so far.
+func (c *AnthropicClient) Usage() core.Usage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.usage
+}
+
 type anthropicResponse struct {
 	Content []struct {
-		Text string `json:"text"`
+		Type  string          `json:"type"`
+		Text  string          `json:"text"`
+		Input json.RawMessage `json:"input"`
 	} `json:"content"`
 	Error *struct {
 		Type    string `json:"type"`
 		Message string `json:"message"`
 	} `json:"error"`
-	Type string `json:"type"`
+	Type  string `json:"type"`
+	Usage struct {
+		InputTokens              int `json:"input_tokens"`
+		OutputTokens             int `json:"output_tokens"`
+		CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+		CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+	} `json:"usage"`
 }
 
 func (c *AnthropicClient) handleResponse(resp *http.Response) (*anthropicResponse, error) {
@@ -84,6 +105,15 @@ func (c *AnthropicClient) handleResponse(resp *http.Response) (*anthropicRespons
 		return nil, fmt.Errorf("API error: %s - %s", response.Error.Type, response.Error.Message)
 	}
 
+	// Cached tokens are not part of input_tokens
+	c.mu.Lock()
+	c.usage = c.usage.Add(core.Usage{
+		InputTokens:  response.Usage.InputTokens + response.Usage.CacheCreationInputTokens + response.Usage.CacheReadInputTokens,
+		OutputTokens: response.Usage.OutputTokens,
+		CachedTokens: response.Usage.CacheReadInputTokens,
+	})
+	c.mu.Unlock()
+
 	return &response, nil
 }
 
@@ -93,11 +123,61 @@ func (c *AnthropicClient) GenerateCommitMessage(ctx context.Context, sysPrompt,
 		prompt += fmt.Sprintf("\n\nPlease focus on the following subject in your commit message: %s", subject)
 	}
 
+	return c.complete(ctx, "", prompt)
+}
+
+func (c *AnthropicClient) Complete(ctx context.Context, sysPrompt, prompt string) (string, error) {
+	return c.complete(ctx, sysPrompt, prompt)
+}
+
+func (c *AnthropicClient) complete(ctx context.Context, sysPrompt, prompt string) (string, error) {
+	response, err := c.send(ctx, c.requestBody(sysPrompt, prompt))
+	if err != nil {
+		return "", err
+	}
+
+	if len(response.Content) == 0 {
+		return "", fmt.Errorf("no content in response")
+	}
+
+	return response.Content[0].Text, nil
+}
+
+// CompleteJSON forces the model to call a tool whose input schema is the
+// given schema, and returns the tool input.
+func (c *AnthropicClient) CompleteJSON(ctx context.Context, sysPrompt, prompt, name string, schema map[string]interface{}) (string, error) {
+	body := c.requestBody(sysPrompt, prompt)
+	body["tools"] = []map[string]interface{}{
+		{
+			"name":         name,
+			"description":  "Record the response in a structured format",
+			"input_schema": schema,
+		},
+	}
+	body["tool_choice"] = map[string]string{
+		"type": "tool",
+		"name": name,
+	}
+
+	response, err := c.send(ctx, body)
+	if err != nil {
+		return "", err
+	}
+
+	for _, content := range response.Content {
+		if content.Type == "tool_use" {
+			return string(content.Input), nil
+		}
+	}
+	return "", fmt.Errorf("no tool use in response")
+}
+
+func (c *AnthropicClient) requestBody(sysPrompt, prompt string) map[string]interface{} {
 	if len(prompt) > MaxTokensInput {
 		prompt = prompt[:MaxTokensInput]
 	}
 
-	requestBody, err := json.Marshal(map[string]interface{}{
+	body := map[string]interface{}{
 		"model":      c.model,
 		"max_tokens": MaxTokensOutput,
 		"messages": []map[string]interface{}{
@@ -106,36 +186,35 @@ func (c *AnthropicClient) GenerateCommitMessage(ctx context.Context, sysPrompt,
 				"content": prompt,
 			},
 		},
-	})
+	}
+	if sysPrompt != "" {
+		body["system"] = sysPrompt
+	}
+	return body
+}
+
+func (c *AnthropicClient) send(ctx context.Context, body map[string]interface{}) (*anthropicResponse, error) {
+	requestBody, err := json.Marshal(body)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal request body: %w", err)
+		return nil, fmt.Errorf("failed to marshal request body: %w", err)
 	}
 
 	req, err := common.NewRequest(http.MethodPost, apiURL, requestBody, c.config)
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req = req.WithContext(ctx)
+	// Debug: log raw request URL and body when DEBUG is set
+	if utils.IsDebug() {
+		log.Debug().Msgf("Anthropic request URL: %s", req.URL.String())
+		log.Debug().Msgf("Anthropic request body: %s", string(requestBody))
 	}
-   req = req.WithContext(ctx)
-   // Debug: log raw request URL and body when DEBUG is set
-   if utils.IsDebug() {
-       log.Debug().Msgf("Anthropic request URL: %s", req.URL.String())
-       log.Debug().Msgf("Anthropic request body: %s", string(requestBody))
-   }
 
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to send request: %w", err)
+		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
 	defer resp.Body.Close()
 
-	response, err := c.handleResponse(resp)
-	if err != nil {
-		return "", err
-	}
-
-	if len(response.Content) == 0 {
-		return "", fmt.Errorf("no content in response")
-	}
-
-	return response.Content[0].Text, nil
+	return c.handleResponse(resp)
 }
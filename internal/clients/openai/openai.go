@@ -1,17 +1,19 @@
 package openai
 
 import (
-   "commi/internal/clients/common"
-   "commi/internal/utils"
-
-   "context"
-   "encoding/json"
-   "fmt"
-   "io"
-   "net/http"
-   "strings"
-
-   "github.com/rs/zerolog/log"
+	"commi/internal/clients/common"
+	"commi/internal/core"
+	"commi/internal/utils"
+
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog/log"
 )
 
 const (
@@ -30,6 +32,9 @@ type OpenAIClient struct {
 	model  string
 	client *http.Client
 	config common.ClientConfig
+
+	mu    sync.Mutex
+	usage core.Usage
 }
 
 func NewOpenAIClient(key string) *OpenAIClient {
@@ -45,15 +50,30 @@ func NewOpenAIClient(key string) *OpenAIClient {
 		model:  defaultModel,
 		client: common.NewHTTPClient(clientConfig),
 		config: clientConfig,
+		usage:  core.Usage{Provider: "openai", Model: defaultModel},
 	}
 }
 
+// Usage returns the tokens used by all requests so far.
+func (c *OpenAIClient) Usage() core.Usage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.usage
+}
+
 type openaiResponse struct {
 	Choices []struct {
 		Message struct {
 			Content string `json:"content"`
 		} `json:"message"`
 	} `json:"choices"`
+	Usage struct {
+		PromptTokens        int `json:"prompt_tokens"`
+		CompletionTokens    int `json:"completion_tokens"`
+		PromptTokensDetails struct {
+			CachedTokens int `json:"cached_tokens"`
+		} `json:"prompt_tokens_details"`
+	} `json:"usage"`
 	Error *struct {
 		Message string `json:"message"`
 		Type    string `json:"type"`
@@ -61,17 +81,17 @@ type openaiResponse struct {
 }
 
 func (c *OpenAIClient) handleResponse(resp *http.Response) (*openaiResponse, error) {
-   body, err := io.ReadAll(resp.Body)
+	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
-   // Debug: log raw response status and body when DEBUG is set
-   if utils.IsDebug() {
-       log.Debug().Msgf("OpenAI response status: %d", resp.StatusCode)
-       log.Debug().Msgf("OpenAI response body: %s", string(body))
-   }
-   if resp.StatusCode != http.StatusOK {
+	// Debug: log raw response status and body when DEBUG is set
+	if utils.IsDebug() {
+		log.Debug().Msgf("OpenAI response status: %d", resp.StatusCode)
+		log.Debug().Msgf("OpenAI response body: %s", string(body))
+	}
+	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("API error: status=%d, body=%s", resp.StatusCode, string(body))
 	}
 
@@ -84,6 +104,14 @@ func (c *OpenAIClient) handleResponse(resp *http.Response) (*openaiResponse, err
 		return nil, fmt.Errorf("API error: %s - %s", response.Error.Type, response.Error.Message)
 	}
 
+	c.mu.Lock()
+	c.usage = c.usage.Add(core.Usage{
+		InputTokens:  response.Usage.PromptTokens,
+		OutputTokens: response.Usage.CompletionTokens,
+		CachedTokens: response.Usage.PromptTokensDetails.CachedTokens,
+	})
+	c.mu.Unlock()
+
 	return &response, nil
 }
 
@@ -93,10 +121,37 @@ func (c *OpenAIClient) GenerateCommitMessage(ctx context.Context, sysPrompt, sta
 		prompt += fmt.Sprintf("\n\nPlease focus on the following subject in your commit message: %s", subject)
 	}
 
+	return c.complete(ctx, sysPrompt, prompt)
+}
+
+func (c *OpenAIClient) Complete(ctx context.Context, sysPrompt, prompt string) (string, error) {
+	return c.complete(ctx, sysPrompt, prompt)
+}
+
+func (c *OpenAIClient) complete(ctx context.Context, sysPrompt, prompt string) (string, error) {
+	return c.send(ctx, c.requestBody(sysPrompt, prompt))
+}
+
+// CompleteJSON asks for a response that strictly follows the given JSON
+// schema.
+func (c *OpenAIClient) CompleteJSON(ctx context.Context, sysPrompt, prompt, name string, schema map[string]interface{}) (string, error) {
+	body := c.requestBody(sysPrompt, prompt)
+	body["response_format"] = map[string]interface{}{
+		"type": "json_schema",
+		"json_schema": map[string]interface{}{
+			"name":   name,
+			"strict": true,
+			"schema": schema,
+		},
+	}
+	return c.send(ctx, body)
+}
+
+func (c *OpenAIClient) requestBody(sysPrompt, prompt string) map[string]interface{} {
 	if len(prompt) > MaxTokensInput {
 		prompt = prompt[:MaxTokensInput]
 	}
-	requestBody, err := json.Marshal(map[string]interface{}{
+	return map[string]interface{}{
 		"model": c.model,
 		"messages": []map[string]string{
 			{
@@ -110,7 +165,11 @@ func (c *OpenAIClient) GenerateCommitMessage(ctx context.Context, sysPrompt, sta
 		},
 		"max_tokens": MaxTokensOutput,
 		// "max_completion_tokens": MaxTokensOutput, // TODO: support gpt models with max_tokens
-	})
+	}
+}
+
+func (c *OpenAIClient) send(ctx context.Context, body map[string]interface{}) (string, error) {
+	requestBody, err := json.Marshal(body)
 	if err != nil {
 		return "", fmt.Errorf("failed to marshal request body: %w", err)
 	}
@@ -119,12 +178,12 @@ func (c *OpenAIClient) GenerateCommitMessage(ctx context.Context, sysPrompt, sta
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %w", err)
 	}
-   req = req.WithContext(ctx)
-   // Debug: log raw request URL and body when DEBUG is set
-   if utils.IsDebug() {
-       log.Debug().Msgf("OpenAI request URL: %s", req.URL.String())
-       log.Debug().Msgf("OpenAI request body: %s", string(requestBody))
-   }
+	req = req.WithContext(ctx)
+	// Debug: log raw request URL and body when DEBUG is set
+	if utils.IsDebug() {
+		log.Debug().Msgf("OpenAI request URL: %s", req.URL.String())
+		log.Debug().Msgf("OpenAI request body: %s", string(requestBody))
+	}
 
 	resp, err := c.client.Do(req)
 	if err != nil {
@@ -1,5 +1,215 @@
 package config
 
-// TODO: add config options from envs or config file
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Ticket modes control what happens with ticket IDs found in the branch name.
+const (
+	TicketModePrompt  = "prompt"
+	TicketModePrefix  = "prefix"
+	TicketModeTrailer = "trailer"
+)
+
+// TrailerSignoff in COMMI_TRAILERS adds a Signed-off-by trailer built from
+// the git user name and email.
+const TrailerSignoff = "signoff"
+
+// Redaction modes control what happens with secrets found in diffs before
+// they are sent to the provider.
+const (
+	RedactMask  = "mask"
+	RedactBlock = "block"
+	RedactOff   = "off"
+)
+
+// FunctionContextAuto adds function context to diffs only when they are
+// small enough for the budget.
+const FunctionContextAuto = "auto"
+
+// DefaultDiffBudget is the diff size in bytes up to which richer diffs are
+// used, leaving room for the rest of the prompt within the input limit.
+const DefaultDiffBudget = 8000
+
+// Commit message styles the commit prompt asks for.
+const (
+	StyleGitmoji      = "gitmoji"
+	StyleConventional = "conventional"
+	StylePlain        = "plain"
+)
+
+// DefaultCacheTTL is how long generated messages are cached by default.
+const DefaultCacheTTL = 24 * time.Hour
+
+// TODO: add config options from a config file
 type Config struct {
+	// BaseBranch is the branch pull requests are compared against.
+	BaseBranch string
+	// BranchPattern is the template for suggested branch names.
+	BranchPattern string
+	// TicketPatterns are regexes used to find ticket IDs in branch names.
+	TicketPatterns []string
+	// TicketModes lists how detected tickets are applied to the commit.
+	TicketModes []string
+	// Trailers are added to every commit, as "Key: Value" or "signoff".
+	Trailers []string
+	// GitBackend selects the git implementation, "exec" or "go-git".
+	GitBackend string
+	// Redact is the secret redaction mode, "mask", "block" or "off".
+	Redact string
+	// MaxDiffLines and MaxFileSize are the thresholds above which a file is
+	// summarized instead of diffed, 0 for the default.
+	MaxDiffLines int
+	MaxFileSize  int64
+	// SemanticDiff adds a summary of changed declarations to the prompt.
+	SemanticDiff bool
+	// DiffContext is the number of context lines, -1 for git's default.
+	DiffContext int
+	// DiffFunctionContext is "true", "false" or "auto".
+	DiffFunctionContext string
+	// DiffAlgorithm is the git diff algorithm, e.g. histogram.
+	DiffAlgorithm string
+	// DiffWhitespace ignores whitespace: "all", "change", "eol" or "blank".
+	DiffWhitespace string
+	// DiffBudget is the diff size in bytes below which automatic function
+	// context is used.
+	DiffBudget int
+	// OutputFormat is how the model returns commit messages, "xml" or
+	// "json" for the provider's structured output.
+	OutputFormat string
+	// WrapWidth is the column generated descriptions are wrapped at, 0 for
+	// the default and negative to not wrap.
+	WrapWidth int
+	// LintTitleLength and LintBodyWidth are the limits checked by the
+	// commit message linter, 0 for the default.
+	LintTitleLength int
+	LintBodyWidth   int
+	// LintForbiddenWords may not appear in commit messages.
+	LintForbiddenWords []string
+	// LintRequireTicket reports commit messages without a ticket ID.
+	LintRequireTicket bool
+	// LintDisable lists lint rules to skip, or "all".
+	LintDisable []string
+	// Prices overrides the price table used to estimate costs, as
+	// "model=input/output[/cached]" in USD per million tokens, separated by
+	// ";".
+	Prices string
+	// CacheTTL is how long generated messages are reused for identical
+	// changes, 0 disables the cache.
+	CacheTTL time.Duration
+	// Language is the language commit messages are written in, empty for
+	// the model's choice.
+	Language string
+	// Style is the commit message style, "gitmoji", "conventional" or
+	// "plain".
+	Style string
+}
+
+// Load reads the configuration from COMMI_* environment variables.
+func Load() *Config {
+	cfg := &Config{
+		BaseBranch:     os.Getenv("COMMI_BASE_BRANCH"),
+		BranchPattern:  os.Getenv("COMMI_BRANCH_PATTERN"),
+		TicketPatterns: splitList(os.Getenv("COMMI_TICKET_PATTERNS"), ";"),
+		TicketModes:    splitList(os.Getenv("COMMI_TICKET_MODE"), ","),
+		Trailers:       splitList(os.Getenv("COMMI_TRAILERS"), ";"),
+		GitBackend:     os.Getenv("COMMI_GIT_BACKEND"),
+		Redact:         os.Getenv("COMMI_REDACT"),
+		DiffContext:    -1,
+		DiffAlgorithm:  os.Getenv("COMMI_DIFF_ALGORITHM"),
+		DiffWhitespace: os.Getenv("COMMI_DIFF_IGNORE_WHITESPACE"),
+		DiffBudget:     DefaultDiffBudget,
+		OutputFormat:   strings.ToLower(os.Getenv("COMMI_OUTPUT_FORMAT")),
+
+		LintForbiddenWords: splitList(os.Getenv("COMMI_LINT_FORBIDDEN_WORDS"), ","),
+		LintDisable:        splitList(os.Getenv("COMMI_LINT_DISABLE"), ","),
+		Prices:             os.Getenv("COMMI_PRICES"),
+		CacheTTL:           DefaultCacheTTL,
+		Language:           os.Getenv("COMMI_LANGUAGE"),
+		Style:              strings.ToLower(os.Getenv("COMMI_STYLE")),
+	}
+	if n, err := strconv.Atoi(os.Getenv("COMMI_DIFF_CONTEXT")); err == nil && n >= 0 {
+		cfg.DiffContext = n
+	}
+	cfg.DiffFunctionContext = FunctionContextAuto
+	if b, err := strconv.ParseBool(os.Getenv("COMMI_DIFF_FUNCTION_CONTEXT")); err == nil {
+		cfg.DiffFunctionContext = strconv.FormatBool(b)
+	}
+	if n, err := strconv.Atoi(os.Getenv("COMMI_DIFF_BUDGET")); err == nil {
+		cfg.DiffBudget = n
+	}
+	if b, err := strconv.ParseBool(os.Getenv("COMMI_SEMANTIC_DIFF")); err == nil {
+		cfg.SemanticDiff = b
+	}
+	if n, err := strconv.Atoi(os.Getenv("COMMI_MAX_DIFF_LINES")); err == nil {
+		cfg.MaxDiffLines = n
+	}
+	if n, err := strconv.ParseInt(os.Getenv("COMMI_MAX_FILE_SIZE"), 10, 64); err == nil {
+		cfg.MaxFileSize = n
+	}
+	if d, err := time.ParseDuration(os.Getenv("COMMI_CACHE_TTL")); err == nil {
+		cfg.CacheTTL = d
+	}
+	if n, err := strconv.Atoi(os.Getenv("COMMI_WRAP_WIDTH")); err == nil {
+		cfg.WrapWidth = n
+	}
+	if n, err := strconv.Atoi(os.Getenv("COMMI_LINT_TITLE_LENGTH")); err == nil {
+		cfg.LintTitleLength = n
+	}
+	if n, err := strconv.Atoi(os.Getenv("COMMI_LINT_BODY_WIDTH")); err == nil {
+		cfg.LintBodyWidth = n
+	}
+	if b, err := strconv.ParseBool(os.Getenv("COMMI_LINT_REQUIRE_TICKET")); err == nil {
+		cfg.LintRequireTicket = b
+	}
+	if len(cfg.TicketModes) == 0 {
+		cfg.TicketModes = []string{TicketModePrompt}
+	}
+	if cfg.Redact == "" {
+		cfg.Redact = RedactMask
+	}
+	if cfg.Style == "" {
+		cfg.Style = StyleGitmoji
+		if _, ok := os.LookupEnv("DISABLE_EMOJI"); ok {
+			cfg.Style = StylePlain
+		}
+	}
+	return cfg
+}
+
+// DataDir is where commi keeps its data, such as the usage ledger:
+// $XDG_DATA_HOME/commi, or ~/.local/share/commi when it isn't set.
+func DataDir() (string, error) {
+	if dir := os.Getenv("XDG_DATA_HOME"); filepath.IsAbs(dir) {
+		return filepath.Join(dir, "commi"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share", "commi"), nil
+}
+
+// HasTicketMode reports whether the given ticket mode is enabled.
+func (c *Config) HasTicketMode(mode string) bool {
+	for _, m := range c.TicketModes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+func splitList(value, sep string) []string {
+	var items []string
+	for _, item := range strings.Split(value, sep) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
 }
@@ -0,0 +1,132 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	DefaultBranchPattern = "{type}/{ticket}-{desc}"
+	maxBranchDescLength  = 40
+)
+
+var ErrNothingToName = errors.New("either changes or a subject are required")
+
+var (
+	slugInvalidRe   = regexp.MustCompile(`[^a-z0-9]+`)
+	ticketInvalidRe = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+	branchDashRe    = regexp.MustCompile(`-{2,}`)
+)
+
+type BranchOptions struct {
+	Status  string
+	Diffs   string
+	Subject string
+	Ticket  string
+	// Pattern may use the {type}, {ticket} and {desc} placeholders.
+	Pattern string
+}
+
+// GenerateBranchNames asks the LLM for a few branch name candidates and
+// renders them with the configured pattern.
+func (c *Core) GenerateBranchNames(ctx context.Context, opts BranchOptions) ([]string, error) {
+	if opts.Diffs == "" && opts.Subject == "" {
+		return nil, fmt.Errorf("invalid options: %w", ErrNothingToName)
+	}
+	if opts.Pattern == "" {
+		opts.Pattern = DefaultBranchPattern
+	}
+
+	var b strings.Builder
+	if opts.Subject != "" {
+		fmt.Fprintf(&b, "Subject:\n\n%s\n\n", opts.Subject)
+	}
+	if opts.Diffs != "" {
+		fmt.Fprintf(&b, "Git status:\n\n%s\n\nGit diffs:\n\n%s\n\n", opts.Status, opts.Diffs)
+	}
+	b.WriteString("Based on this information, suggest branch names in XML format:")
+
+	sys, err := RenderPrompt(PromptBranch, PromptData{Subject: opts.Subject})
+	if err != nil {
+		return nil, err
+	}
+
+	defer c.measure()()
+
+	xmlContent, err := c.client.Complete(ctx, sys, b.String())
+	if err != nil {
+		return nil, fmt.Errorf("LLM client failed: %w", err)
+	}
+
+	var suggestions []branchSuggestion
+	err = c.withRepair(ctx, sys, xmlContent, func(content string) (err error) {
+		suggestions, err = parseBranchSuggestions(content)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var names []string
+	for _, s := range suggestions {
+		name := FormatBranchName(opts.Pattern, s.Type, opts.Ticket, s.Description)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no usable branch names in LLM response")
+	}
+	return names, nil
+}
+
+// Slugify lowercases s and replaces everything but letters and digits with
+// dashes, cutting it at a word boundary when it's too long.
+func Slugify(s string) string {
+	slug := strings.Trim(slugInvalidRe.ReplaceAllString(strings.ToLower(s), "-"), "-")
+	if len(slug) > maxBranchDescLength {
+		// Look one past the limit, a word ending right at it is kept
+		if i := strings.LastIndex(slug[:maxBranchDescLength+1], "-"); i > 0 {
+			slug = slug[:i]
+		} else {
+			slug = slug[:maxBranchDescLength]
+		}
+	}
+	return slug
+}
+
+// ticketSlug keeps the case of a ticket, which is usually meaningful as in
+// "CR-22", but replaces what git doesn't allow in a branch name, such as
+// spaces, "~", "^", ":" and "..", with dashes.
+func ticketSlug(ticket string) string {
+	slug := ticketInvalidRe.ReplaceAllString(ticket, "-")
+	for strings.Contains(slug, "..") {
+		slug = strings.ReplaceAll(slug, "..", ".")
+	}
+	slug = strings.TrimSuffix(slug, ".lock")
+	return strings.Trim(slug, "-.")
+}
+
+// FormatBranchName fills the pattern placeholders and cleans up separators
+// left behind by empty values.
+func FormatBranchName(pattern, branchType, ticket, desc string) string {
+	name := strings.NewReplacer(
+		"{type}", Slugify(branchType),
+		"{ticket}", ticketSlug(ticket),
+		"{desc}", Slugify(desc),
+	).Replace(pattern)
+
+	name = branchDashRe.ReplaceAllString(name, "-")
+	name = strings.ReplaceAll(name, "/-", "/")
+	name = strings.ReplaceAll(name, "-/", "/")
+	for strings.Contains(name, "//") {
+		name = strings.ReplaceAll(name, "//", "/")
+	}
+	return strings.Trim(name, "-/")
+}
@@ -0,0 +1,68 @@
+package core
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"Add retries to the HTTP client", "add-retries-to-the-http-client"},
+		{"  Fix: crash on *empty* diff!  ", "fix-crash-on-empty-diff"},
+		{"", ""},
+		// A word ending at exactly 40 characters is kept
+		{"support retries with exponential backoff now", "support-retries-with-exponential-backoff"},
+		// Otherwise the slug is cut after the last word that fits
+		{"support retries with exponential backoffs", "support-retries-with-exponential"},
+		{strings.Repeat("a", 50), strings.Repeat("a", 40)},
+	}
+	for _, tt := range tests {
+		if got := Slugify(tt.in); got != tt.want {
+			t.Errorf("Slugify(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+		if got := Slugify(tt.in); len(got) > maxBranchDescLength {
+			t.Errorf("Slugify(%q) = %q, longer than %d", tt.in, got, maxBranchDescLength)
+		}
+	}
+}
+
+func TestFormatBranchName(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		ticket  string
+		want    string
+	}{
+		{"default", DefaultBranchPattern, "CR-22", "feat/CR-22-add-retries"},
+		{"empty ticket", DefaultBranchPattern, "", "feat/add-retries"},
+		{"no ticket placeholder", "{type}/{desc}", "CR-22", "feat/add-retries"},
+		{"ticket directory", "{ticket}/{desc}", "", "add-retries"},
+		{"spaces", DefaultBranchPattern, "PROJ 12", "feat/PROJ-12-add-retries"},
+		{"ref syntax", DefaultBranchPattern, "~a^b:c?d*e[f", "feat/a-b-c-d-e-f-add-retries"},
+		{"dots", DefaultBranchPattern, "..v1..2.lock", "feat/v1.2-add-retries"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FormatBranchName(tt.pattern, "Feat", tt.ticket, "Add retries")
+			if got != tt.want {
+				t.Errorf("FormatBranchName(%q, %q) = %q, want %q", tt.pattern, tt.ticket, got, tt.want)
+			}
+			checkBranchName(t, got)
+		})
+	}
+}
+
+// checkBranchName fails if git doesn't accept name as a branch name.
+func checkBranchName(t *testing.T, name string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		return
+	}
+	if output, err := exec.Command("git", "check-ref-format", "--branch", name).CombinedOutput(); err != nil {
+		t.Errorf("git rejects %q: %s", name, output)
+	}
+}
@@ -0,0 +1,109 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Cache stores generated commit messages by the hash of their inputs, so
+// identical requests aren't billed twice.
+type Cache interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, value []byte) error
+}
+
+// FileCache keeps one file per entry in a directory. Entries older than the
+// TTL are ignored and removed on the next write.
+type FileCache struct {
+	dir string
+	ttl time.Duration
+}
+
+func NewFileCache(dir string, ttl time.Duration) *FileCache {
+	return &FileCache{dir: dir, ttl: ttl}
+}
+
+func (c *FileCache) Get(key string) ([]byte, bool) {
+	path := filepath.Join(c.dir, key)
+	info, err := os.Stat(path)
+	if err != nil || time.Since(info.ModTime()) > c.ttl {
+		return nil, false
+	}
+	value, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	return value, true
+}
+
+// Set writes an entry only the current user can read, as generated messages
+// are derived from the diffs of possibly private repositories.
+func (c *FileCache) Set(key string, value []byte) error {
+	if err := os.MkdirAll(c.dir, 0700); err != nil {
+		return err
+	}
+	// MkdirAll leaves the mode of a directory created by older versions
+	if err := os.Chmod(c.dir, 0700); err != nil {
+		return err
+	}
+	c.prune()
+	return os.WriteFile(filepath.Join(c.dir, key), value, 0600)
+}
+
+// prune removes expired entries.
+func (c *FileCache) prune() {
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		if info, err := e.Info(); err == nil && time.Since(info.ModTime()) > c.ttl {
+			os.Remove(filepath.Join(c.dir, e.Name()))
+		}
+	}
+}
+
+// SetCache enables caching of generated commit messages.
+func (c *Core) SetCache(cache Cache) {
+	c.cache = cache
+}
+
+// cacheKey hashes everything that determines the model's answer.
+func (c *Core) cacheKey(parts ...string) string {
+	provider, model := c.Model()
+	h := sha256.New()
+	for _, p := range append([]string{c.format, provider, model}, parts...) {
+		// The separator keeps ("ab", "c") and ("a", "bc") apart
+		h.Write([]byte(p + "\x00"))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (c *Core) cachedCommit(key string) (*CommitMessage, bool) {
+	if c.cache == nil {
+		return nil, false
+	}
+	value, ok := c.cache.Get(key)
+	if !ok {
+		return nil, false
+	}
+	var commit CommitMessage
+	if err := json.Unmarshal(value, &commit); err != nil || commit.Title == "" {
+		return nil, false
+	}
+	return &commit, true
+}
+
+func (c *Core) cacheCommit(key string, commit *CommitMessage) {
+	if c.cache == nil {
+		return
+	}
+	if value, err := json.Marshal(commit); err == nil {
+		// The cache only saves a request, a failed write changes nothing
+		_ = c.cache.Set(key, value)
+	}
+}
@@ -0,0 +1,121 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// fakeClient answers every request with response and reports a fixed usage.
+type fakeClient struct {
+	usage    Usage
+	response string
+}
+
+func (f *fakeClient) GenerateCommitMessage(ctx context.Context, systemPrompt, status, diffs, subject string) (string, error) {
+	return f.response, nil
+}
+
+func (f *fakeClient) Complete(ctx context.Context, systemPrompt, prompt string) (string, error) {
+	return f.response, nil
+}
+
+func (f *fakeClient) Usage() Usage {
+	return f.usage
+}
+
+func TestFileCache(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "commi")
+	cache := NewFileCache(dir, time.Hour)
+
+	if _, ok := cache.Get("missing"); ok {
+		t.Error("Get() of a missing entry is ok")
+	}
+	if err := cache.Set("key", []byte("value")); err != nil {
+		t.Fatal(err)
+	}
+	if value, ok := cache.Get("key"); !ok || string(value) != "value" {
+		t.Errorf("Get() = %q, %t, want value", value, ok)
+	}
+
+	for path, want := range map[string]os.FileMode{dir: 0700, filepath.Join(dir, "key"): 0600} {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if mode := info.Mode().Perm(); mode != want {
+			t.Errorf("%s has mode %o, want %o", path, mode, want)
+		}
+	}
+}
+
+func TestFileCacheExpiry(t *testing.T) {
+	dir := t.TempDir()
+	cache := NewFileCache(dir, time.Hour)
+	if err := cache.Set("old", []byte("value")); err != nil {
+		t.Fatal(err)
+	}
+	past := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, "old"), past, past); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := cache.Get("old"); ok {
+		t.Error("Get() of an expired entry is ok")
+	}
+	if err := cache.Set("new", []byte("value")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "old")); !os.IsNotExist(err) {
+		t.Errorf("expired entry wasn't pruned on write: %v", err)
+	}
+}
+
+func TestCachedCommitCorrupt(t *testing.T) {
+	dir := t.TempDir()
+	c := NewCore(&fakeClient{})
+	c.SetCache(NewFileCache(dir, time.Hour))
+
+	for name, value := range map[string]string{
+		"corrupt":  `{"title": "Add`,
+		"no title": `{"title": "", "description": "Body"}`,
+	} {
+		if err := os.WriteFile(filepath.Join(dir, "key"), []byte(value), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if commit, ok := c.cachedCommit("key"); ok {
+			t.Errorf("%s entry: cachedCommit() = %+v, want a miss", name, commit)
+		}
+	}
+
+	c.cacheCommit("key", &CommitMessage{Title: "Add retries"})
+	if commit, ok := c.cachedCommit("key"); !ok || commit.Title != "Add retries" {
+		t.Errorf("cachedCommit() = %+v, %t, want the cached commit", commit, ok)
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	newCore := func(provider, model, format string) *Core {
+		c := NewCore(&fakeClient{usage: Usage{Provider: provider, Model: model}})
+		c.format = format
+		return c
+	}
+	base := newCore("anthropic", "claude", OutputXML).cacheKey("status", "diff")
+
+	if key := newCore("anthropic", "claude", OutputXML).cacheKey("status", "diff"); key != base {
+		t.Error("cacheKey() differs for the same inputs")
+	}
+	tests := map[string]string{
+		"provider": newCore("openai", "claude", OutputXML).cacheKey("status", "diff"),
+		"model":    newCore("anthropic", "other", OutputXML).cacheKey("status", "diff"),
+		"format":   newCore("anthropic", "claude", OutputJSON).cacheKey("status", "diff"),
+		"parts":    newCore("anthropic", "claude", OutputXML).cacheKey("statusd", "iff"),
+	}
+	for name, key := range tests {
+		if key == base {
+			t.Errorf("cacheKey() doesn't change with the %s", name)
+		}
+	}
+}
@@ -0,0 +1,284 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ChangeType is a Keep a Changelog section.
+type ChangeType string
+
+const (
+	Added      ChangeType = "Added"
+	Changed    ChangeType = "Changed"
+	Deprecated ChangeType = "Deprecated"
+	Removed    ChangeType = "Removed"
+	Fixed      ChangeType = "Fixed"
+	Security   ChangeType = "Security"
+)
+
+// ChangeTypes lists the sections in the order they appear in a changelog.
+var ChangeTypes = []ChangeType{Added, Changed, Deprecated, Removed, Fixed, Security}
+
+// ChangelogEntry is a single commit in the changelog.
+type ChangelogEntry struct {
+	Type        ChangeType
+	Description string
+}
+
+var conventionalTypes = map[string]ChangeType{
+	"feat":      Added,
+	"fix":       Fixed,
+	"perf":      Changed,
+	"refactor":  Changed,
+	"style":     Changed,
+	"docs":      Changed,
+	"build":     Changed,
+	"ci":        Changed,
+	"chore":     Changed,
+	"test":      Changed,
+	"revert":    Changed,
+	"remove":    Removed,
+	"deprecate": Deprecated,
+	"security":  Security,
+}
+
+// gitmojiTypes maps gitmoji (both unicode and :code: forms) to sections.
+var gitmojiTypes = map[string]ChangeType{
+	"✨": Added, ":sparkles:": Added,
+	"🎉": Added, ":tada:": Added,
+	"➕": Added, ":heavy_plus_sign:": Added,
+	"🐛": Fixed, ":bug:": Fixed,
+	"🚑": Fixed, ":ambulance:": Fixed,
+	"🩹": Fixed, ":adhesive_bandage:": Fixed,
+	"✏": Fixed, ":pencil2:": Fixed,
+	"🔥": Removed, ":fire:": Removed,
+	"⚰": Removed, ":coffin:": Removed,
+	"➖": Removed, ":heavy_minus_sign:": Removed,
+	"🗑": Deprecated, ":wastebasket:": Deprecated,
+	"🔒": Security, ":lock:": Security,
+	"🛂": Security, ":passport_control:": Security,
+	"♻": Changed, ":recycle:": Changed,
+	"⚡": Changed, ":zap:": Changed,
+	"🎨": Changed, ":art:": Changed,
+	"📝": Changed, ":memo:": Changed,
+	"💄": Changed, ":lipstick:": Changed,
+	"⬆": Changed, ":arrow_up:": Changed,
+	"⬇": Changed, ":arrow_down:": Changed,
+	"🔧": Changed, ":wrench:": Changed,
+	"✅": Changed, ":white_check_mark:": Changed,
+	"🚀": Changed, ":rocket:": Changed,
+	"🚚": Changed, ":truck:": Changed,
+	"💥": Changed, ":boom:": Changed,
+	"⏪": Changed, ":rewind:": Changed,
+}
+
+var (
+	conventionalRe = regexp.MustCompile(`^(\w+)(\([^)]*\))?!?:\s*(.+)$`)
+	gitmojiCodeRe  = regexp.MustCompile(`^(:[a-z0-9_+-]+:)\s*(.+)$`)
+)
+
+// ClassifyCommit detects the change type from a conventional commit or
+// gitmoji prefix. It returns false when the subject has no known prefix.
+func ClassifyCommit(subject string) (ChangelogEntry, bool) {
+	subject = strings.TrimSpace(subject)
+
+	if m := conventionalRe.FindStringSubmatch(subject); m != nil {
+		if t, ok := conventionalTypes[strings.ToLower(m[1])]; ok {
+			return ChangelogEntry{Type: t, Description: m[3]}, true
+		}
+	}
+
+	if m := gitmojiCodeRe.FindStringSubmatch(subject); m != nil {
+		if t, ok := gitmojiTypes[m[1]]; ok {
+			return ChangelogEntry{Type: t, Description: m[2]}, true
+		}
+	}
+
+	// Unicode gitmoji, possibly followed by a variation selector
+	if r := []rune(subject); len(r) > 0 {
+		if t, ok := gitmojiTypes[string(r[0])]; ok {
+			rest := strings.TrimLeft(string(r[1:]), "\ufe0f ")
+			return ChangelogEntry{Type: t, Description: rest}, true
+		}
+	}
+
+	return ChangelogEntry{Description: subject}, false
+}
+
+// ClassifyCommits asks the LLM to pick a change type for each subject.
+// Subjects the model doesn't classify default to Changed.
+func (c *Core) ClassifyCommits(ctx context.Context, subjects []string) ([]ChangeType, error) {
+	var b strings.Builder
+	b.WriteString("Commits:\n\n")
+	for i, s := range subjects {
+		fmt.Fprintf(&b, "%d. %s\n", i+1, s)
+	}
+	b.WriteString("\nBased on this information, classify every commit in XML format:")
+
+	sys, err := RenderPrompt(PromptChangelog, PromptData{})
+	if err != nil {
+		return nil, err
+	}
+
+	defer c.measure()()
+
+	xmlContent, err := c.client.Complete(ctx, sys, b.String())
+	if err != nil {
+		return nil, fmt.Errorf("LLM client failed: %w", err)
+	}
+
+	var classified map[int]ChangeType
+	err = c.withRepair(ctx, sys, xmlContent, func(content string) (err error) {
+		classified, err = parseChangeTypes(content)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	types := make([]ChangeType, len(subjects))
+	for i := range types {
+		types[i] = Changed
+		if t, ok := classified[i+1]; ok {
+			types[i] = t
+		}
+	}
+	return types, nil
+}
+
+func parseChangeType(s string) (ChangeType, bool) {
+	for _, t := range ChangeTypes {
+		if strings.EqualFold(string(t), strings.TrimSpace(s)) {
+			return t, true
+		}
+	}
+	return "", false
+}
+
+// RenderChangelog formats entries as a Keep a Changelog release section.
+func RenderChangelog(release, date string, entries []ChangelogEntry) string {
+	var b strings.Builder
+	if date != "" {
+		fmt.Fprintf(&b, "## [%s] - %s\n", release, date)
+	} else {
+		fmt.Fprintf(&b, "## [%s]\n", release)
+	}
+
+	for _, t := range ChangeTypes {
+		var lines []string
+		for _, e := range entries {
+			if e.Type == t {
+				lines = append(lines, "- "+e.Description)
+			}
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n### %s\n\n%s\n", t, strings.Join(lines, "\n"))
+	}
+	return b.String()
+}
+
+// InsertChangelog adds a release section to an existing changelog. Entries
+// are merged into a section of the same release, e.g. Unreleased, skipping
+// ones already listed; otherwise the section goes above the latest release.
+func InsertChangelog(content, release, date string, entries []ChangelogEntry) string {
+	lines := strings.Split(content, "\n")
+	first := -1
+	for i, line := range lines {
+		if !strings.HasPrefix(line, "## ") {
+			continue
+		}
+		if first == -1 {
+			first = i
+		}
+		if strings.EqualFold(releaseName(line), release) {
+			return mergeRelease(lines, i, entries)
+		}
+	}
+
+	section := RenderChangelog(release, date, entries)
+	if first == -1 {
+		return strings.TrimRight(content, "\n") + "\n\n" + section
+	}
+	before := strings.Join(lines[:first], "\n")
+	if first > 0 {
+		before += "\n"
+	}
+	return before + section + "\n" + strings.Join(lines[first:], "\n")
+}
+
+// releaseName returns the name of a release heading such as
+// "## [1.2.0] - 2024-05-01".
+func releaseName(heading string) string {
+	name, _, _ := strings.Cut(strings.TrimPrefix(heading, "## "), " - ")
+	return strings.Trim(strings.TrimSpace(name), "[]")
+}
+
+// mergeRelease adds the entries to the release whose heading is at
+// lines[heading].
+func mergeRelease(lines []string, heading int, entries []ChangelogEntry) string {
+	end := heading + 1
+	for end < len(lines) && !strings.HasPrefix(lines[end], "## ") {
+		end++
+	}
+	body := slices.Clone(lines[heading+1 : end])
+
+	listed := make(map[string]bool)
+	for _, line := range body {
+		if item, ok := strings.CutPrefix(strings.TrimSpace(line), "- "); ok {
+			listed[item] = true
+		}
+	}
+	for _, t := range ChangeTypes {
+		var items []string
+		for _, e := range entries {
+			if e.Type == t && !listed[e.Description] {
+				listed[e.Description] = true
+				items = append(items, "- "+e.Description)
+			}
+		}
+		if len(items) > 0 {
+			body = addToSubsection(body, t, items)
+		}
+	}
+
+	merged := slices.Concat(lines[:heading+1], body, lines[end:])
+	return strings.Join(merged, "\n")
+}
+
+// addToSubsection appends items to the "### <type>" subsection of a release
+// body, creating it in Keep a Changelog order when it is missing.
+func addToSubsection(body []string, t ChangeType, items []string) []string {
+	heading := "### " + string(t)
+	if start := slices.IndexFunc(body, func(line string) bool { return strings.TrimSpace(line) == heading }); start != -1 {
+		end := start + 1
+		for end < len(body) && !strings.HasPrefix(body[end], "### ") {
+			end++
+		}
+		for end > start+1 && strings.TrimSpace(body[end-1]) == "" {
+			end--
+		}
+		return slices.Insert(body, end, items...)
+	}
+
+	block := append([]string{heading, ""}, items...)
+	order := slices.Index(ChangeTypes, t)
+	for i, line := range body {
+		name, ok := strings.CutPrefix(strings.TrimSpace(line), "### ")
+		if ok && slices.Index(ChangeTypes, ChangeType(name)) > order {
+			return slices.Insert(body, i, append(block, "")...)
+		}
+	}
+
+	// Last subsection, keeping the blank lines before the next release
+	end := len(body)
+	for end > 0 && strings.TrimSpace(body[end-1]) == "" {
+		end--
+	}
+	return slices.Concat(body[:end], []string{""}, block, body[end:])
+}
@@ -0,0 +1,83 @@
+package core
+
+import (
+	"testing"
+)
+
+func TestInsertChangelog(t *testing.T) {
+	entries := []ChangelogEntry{
+		{Type: Added, Description: "Add retries"},
+		{Type: Fixed, Description: "Fix crash on empty diff"},
+	}
+	tests := []struct {
+		name    string
+		content string
+		release string
+		date    string
+		want    string
+	}{
+		{
+			name:    "above the latest release",
+			content: "# Changelog\n\n## [1.0.0] - 2024-01-01\n\n### Added\n\n- First release\n",
+			release: "1.1.0",
+			date:    "2024-02-01",
+			want: "# Changelog\n\n## [1.1.0] - 2024-02-01\n\n### Added\n\n- Add retries\n\n### Fixed\n\n- Fix crash on empty diff\n\n" +
+				"## [1.0.0] - 2024-01-01\n\n### Added\n\n- First release\n",
+		},
+		{
+			name:    "heading on the first line",
+			content: "## [1.0.0]\n\n- First release\n",
+			release: "Unreleased",
+			want:    "## [Unreleased]\n\n### Added\n\n- Add retries\n\n### Fixed\n\n- Fix crash on empty diff\n\n## [1.0.0]\n\n- First release\n",
+		},
+		{
+			name:    "no releases yet",
+			content: "# Changelog\n",
+			release: "Unreleased",
+			want:    "# Changelog\n\n## [Unreleased]\n\n### Added\n\n- Add retries\n\n### Fixed\n\n- Fix crash on empty diff\n",
+		},
+		{
+			name: "merge into unreleased",
+			content: "# Changelog\n\n## [Unreleased]\n\n### Added\n\n- Add retries\n- Add a flag\n\n### Security\n\n- Mask tokens\n\n" +
+				"## [1.0.0] - 2024-01-01\n\n### Fixed\n\n- Old fix\n",
+			release: "Unreleased",
+			want: "# Changelog\n\n## [Unreleased]\n\n### Added\n\n- Add retries\n- Add a flag\n\n### Fixed\n\n- Fix crash on empty diff\n\n### Security\n\n- Mask tokens\n\n" +
+				"## [1.0.0] - 2024-01-01\n\n### Fixed\n\n- Old fix\n",
+		},
+		{
+			name:    "merge into unbracketed unreleased at the end",
+			content: "## Unreleased\n\n### Added\n\n- Add a flag\n",
+			release: "Unreleased",
+			want:    "## Unreleased\n\n### Added\n\n- Add a flag\n- Add retries\n\n### Fixed\n\n- Fix crash on empty diff\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := InsertChangelog(tt.content, tt.release, tt.date, entries); got != tt.want {
+				t.Errorf("InsertChangelog() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClassifyCommit(t *testing.T) {
+	tests := []struct {
+		subject string
+		want    ChangelogEntry
+		ok      bool
+	}{
+		{"feat(api): Add retries", ChangelogEntry{Type: Added, Description: "Add retries"}, true},
+		{"fix!: Drop empty hunks", ChangelogEntry{Type: Fixed, Description: "Drop empty hunks"}, true},
+		{"remove: Legacy config", ChangelogEntry{Type: Removed, Description: "Legacy config"}, true},
+		{`revert: "Add retries"`, ChangelogEntry{Type: Changed, Description: `"Add retries"`}, true},
+		{":rewind: Undo the cache change", ChangelogEntry{Type: Changed, Description: "Undo the cache change"}, true},
+		{"🐛 Fix crash", ChangelogEntry{Type: Fixed, Description: "Fix crash"}, true},
+		{"Update the README", ChangelogEntry{Description: "Update the README"}, false},
+	}
+	for _, tt := range tests {
+		got, ok := ClassifyCommit(tt.subject)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ClassifyCommit(%q) = %+v, %t, want %+v, %t", tt.subject, got, ok, tt.want, tt.ok)
+		}
+	}
+}
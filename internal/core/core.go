@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 )
 
 var (
@@ -13,10 +14,44 @@ var (
 
 type LLMClient interface {
 	GenerateCommitMessage(ctx context.Context, systemPrompt, status, diffs, subject string) (string, error)
+	Complete(ctx context.Context, systemPrompt, prompt string) (string, error)
+}
+
+// StructuredClient is implemented by clients that can constrain a response
+// to a JSON schema, using the provider's structured output support.
+type StructuredClient interface {
+	CompleteJSON(ctx context.Context, systemPrompt, prompt, name string, schema map[string]interface{}) (string, error)
+}
+
+// Output formats for commit messages. OutputXML works with any client,
+// OutputJSON requires a StructuredClient.
+const (
+	OutputXML  = "xml"
+	OutputJSON = "json"
+)
+
+// commitSchema describes the JSON commit message of OutputJSON.
+var commitSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"title": map[string]interface{}{
+			"type":        "string",
+			"description": "Commit title, at most 72 characters",
+		},
+		"description": map[string]interface{}{
+			"type":        "string",
+			"description": "Commit description, empty for trivial changes",
+		},
+	},
+	"required":             []string{"title", "description"},
+	"additionalProperties": false,
 }
 
 type Core struct {
-	client LLMClient
+	client  LLMClient
+	format  string
+	onUsage func(Usage)
+	cache   Cache
 }
 
 func NewCore(client LLMClient) *Core {
@@ -25,12 +60,32 @@ func NewCore(client LLMClient) *Core {
 	}
 	return &Core{
 		client: client,
+		format: OutputXML,
 	}
 }
 
+// SetOutputFormat selects how the model returns commit messages, OutputXML
+// or OutputJSON. An empty format keeps the default.
+func (c *Core) SetOutputFormat(format string) error {
+	switch format {
+	case "":
+	case OutputXML:
+		c.format = format
+	case OutputJSON:
+		if _, ok := c.client.(StructuredClient); !ok {
+			return fmt.Errorf("the LLM client doesn't support %s output", format)
+		}
+		c.format = format
+	default:
+		return fmt.Errorf("unknown output format %q, expected %s or %s", format, OutputXML, OutputJSON)
+	}
+	return nil
+}
+
 type CommitMessage struct {
-	Title   string
-	Message string
+	Title    string
+	Message  string
+	Trailers []Trailer
 }
 
 type GenerateOptions struct {
@@ -38,6 +93,37 @@ type GenerateOptions struct {
 	Status       string
 	Diffs        string
 	Subject      string
+	// Files lists changed files whose content was summarized rather than
+	// diffed, e.g. binary or oversized files.
+	Files []FileInfo
+	// APIChanges is a structured summary of changed declarations, see
+	// SummarizeChanges. It is placed ahead of the raw diffs.
+	APIChanges string
+	// Format controls how the generated message is cleaned up.
+	Format FormatOptions
+	// NoCache skips the cache lookup, the new message is still cached.
+	NoCache bool
+}
+
+// FileInfo is a changed file and how it was classified, such as "binary",
+// "large" or "generated".
+type FileInfo struct {
+	Path string
+	Kind string
+}
+
+// describeFiles tells the model which files only have a summary in the
+// diffs, so it doesn't guess at their content.
+func describeFiles(files []FileInfo) string {
+	if len(files) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("\n\nThese files are summarized instead of diffed, describe them by their role and size change only:\n")
+	for _, f := range files {
+		fmt.Fprintf(&b, "- %s (%s)\n", f.Path, f.Kind)
+	}
+	return b.String()
 }
 
 func (o *GenerateOptions) validate() error {
@@ -50,26 +136,110 @@ func (o *GenerateOptions) validate() error {
 	return nil
 }
 
-func (c *Core) GenerateCommit(ctx context.Context, opts GenerateOptions) (*CommitMessage, error) {
+// GenerateResult is a generated commit message and the tokens used to
+// generate it.
+type GenerateResult struct {
+	Commit *CommitMessage
+	Usage  Usage
+	// Cached is set when the message came from the cache.
+	Cached bool
+}
+
+func (c *Core) GenerateCommit(ctx context.Context, opts GenerateOptions) (*GenerateResult, error) {
 	if err := opts.validate(); err != nil {
 		return nil, fmt.Errorf("invalid options: %w", err)
 	}
 
+	diffs := opts.Diffs
+	if opts.APIChanges != "" {
+		diffs = "API changes (+ added, - removed, ~ signature changed, * changed):\n\n" + opts.APIChanges + "\n" + diffs
+	}
+
+	status := opts.Status + describeFiles(opts.Files)
+	key := c.cacheKey(opts.SystemPrompt, status, diffs, opts.Subject)
+	if commit, ok := c.cachedCommit(key); ok && !opts.NoCache {
+		FormatCommitMessage(commit, opts.Format)
+		return &GenerateResult{Commit: commit, Cached: true}, nil
+	}
+
+	generate := c.generateCommitXML
+	if c.format == OutputJSON {
+		generate = c.generateCommitJSON
+	}
+	measure := c.measure()
+	commit, err := generate(ctx, opts.SystemPrompt, status, diffs, opts.Subject)
+	usage := measure()
+	if err != nil {
+		return nil, err
+	}
+	c.cacheCommit(key, commit)
+
+	FormatCommitMessage(commit, opts.Format)
+	return &GenerateResult{Commit: commit, Usage: usage}, nil
+}
+
+func (c *Core) generateCommitXML(ctx context.Context, systemPrompt, status, diffs, subject string) (*CommitMessage, error) {
 	xmlContent, err := c.client.GenerateCommitMessage(
 		ctx,
-		opts.SystemPrompt,
-		opts.Status,
-		opts.Diffs,
-		opts.Subject,
+		systemPrompt,
+		status,
+		diffs,
+		subject,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("LLM client failed: %w", err)
 	}
 
-	commit, err := parseCommitMessage(xmlContent)
+	var commit *CommitMessage
+	err = c.withRepair(ctx, systemPrompt, xmlContent, func(content string) (err error) {
+		commit, err = parseCommitMessage(content)
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse LLM response: %w", err)
+		return nil, err
 	}
 
 	return commit, nil
 }
+
+// generateCommitJSON asks for the commit message as structured output.
+func (c *Core) generateCommitJSON(ctx context.Context, systemPrompt, status, diffs, subject string) (*CommitMessage, error) {
+	prompt := fmt.Sprintf("Git status:\n\n%s\n\nGit diffs:\n\n%s\n\nBased on this information, generate a good and descriptive commit message:", status, diffs)
+	if subject != "" {
+		prompt += fmt.Sprintf("\n\nPlease focus on the following subject in your commit message: %s", subject)
+	}
+
+	jsonContent, err := c.client.(StructuredClient).CompleteJSON(ctx, systemPrompt, prompt, "commit_message", commitSchema)
+	if err != nil {
+		return nil, fmt.Errorf("LLM client failed: %w", err)
+	}
+
+	var commit *CommitMessage
+	err = c.withRepair(ctx, systemPrompt, jsonContent, func(content string) (err error) {
+		commit, err = parseCommitJSON(content)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return commit, nil
+}
+
+// withRepair parses a response and, when that fails, gives the model one
+// chance to correct it.
+func (c *Core) withRepair(ctx context.Context, systemPrompt, content string, parse func(string) error) error {
+	err := parse(content)
+	if err == nil {
+		return nil
+	}
+
+	repaired, repairErr := c.client.Complete(ctx, systemPrompt, fmt.Sprintf(RepairPrompt, err, content))
+	if repairErr != nil {
+		return fmt.Errorf("failed to parse LLM response: %w", err)
+	}
+	if err := parse(repaired); err != nil {
+		return fmt.Errorf("failed to parse LLM response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+package core
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultWrapWidth is the column descriptions are wrapped at by default.
+const DefaultWrapWidth = 72
+
+// FormatOptions control how generated commit messages are cleaned up.
+type FormatOptions struct {
+	// Width is the column the description is wrapped at, 0 for
+	// DefaultWrapWidth and negative to not wrap.
+	Width int
+	// StripEmoji removes emoji and gitmoji shortcodes.
+	StripEmoji bool
+}
+
+func (o FormatOptions) width() int {
+	if o.Width == 0 {
+		return DefaultWrapWidth
+	}
+	return o.Width
+}
+
+// FormatCommitMessage tidies a parsed commit message in place.
+func FormatCommitMessage(commit *CommitMessage, opts FormatOptions) {
+	title := commit.Title
+	if opts.StripEmoji {
+		title = stripEmoji(title)
+	}
+	commit.Title = strings.Join(strings.Fields(title), " ")
+	commit.Message = FormatDescription(commit.Message, opts)
+}
+
+// FormatDescription dedents a description, typically indented to match the
+// XML it came in, uses "-" for every bullet and wraps long lines. Code
+// blocks are kept as they are.
+func FormatDescription(text string, opts FormatOptions) string {
+	lines := dedent(strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n"))
+
+	var out []string
+	fenced := false
+	for _, line := range lines {
+		line = strings.TrimRight(line, " \t")
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "```"):
+			fenced = !fenced
+			out = append(out, line)
+			continue
+		case fenced, strings.HasPrefix(line, "    "), strings.HasPrefix(line, "\t"):
+			out = append(out, line)
+			continue
+		}
+
+		if opts.StripEmoji {
+			line = strings.TrimRight(stripEmoji(line), " ")
+		}
+		if line == "" {
+			// Collapse runs of blank lines
+			if len(out) > 0 && out[len(out)-1] != "" {
+				out = append(out, "")
+			}
+			continue
+		}
+		line = bulletMarker.ReplaceAllString(line, "$1- ")
+		if opts.width() > 0 {
+			out = append(out, WrapLine(line, opts.width())...)
+		} else {
+			out = append(out, line)
+		}
+	}
+	return strings.Trim(strings.Join(out, "\n"), "\n")
+}
+
+var bulletMarker = regexp.MustCompile(`^(\s*)(?:[*+•·‣◦–—]|-)\s+`)
+
+// dedent removes the indentation shared by all non-blank lines. A first
+// line without indentation, cut right after the opening tag, is ignored.
+func dedent(lines []string) []string {
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	if len(lines) == 0 {
+		return lines
+	}
+
+	indent := -1
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		n := len(line) - len(strings.TrimLeft(line, " \t"))
+		if i == 0 && n == 0 && len(lines) > 1 {
+			continue
+		}
+		if indent == -1 || n < indent {
+			indent = n
+		}
+	}
+	if indent <= 0 {
+		return lines
+	}
+
+	result := make([]string, len(lines))
+	for i, line := range lines {
+		n := len(line) - len(strings.TrimLeft(line, " \t"))
+		result[i] = line[min(n, indent):]
+	}
+	return result
+}
+
+var emojiShortcode = regexp.MustCompile(`(^|\s):[a-z0-9_+-]+:(\s|$)`)
+
+// stripEmoji removes emoji, their modifiers and gitmoji shortcodes such as
+// ":sparkles:", and the spaces they leave behind.
+func stripEmoji(s string) string {
+	indent := s[:len(s)-len(strings.TrimLeft(s, " \t"))]
+	s = emojiShortcode.ReplaceAllString(s, "$1$2")
+	s = strings.Map(func(r rune) rune {
+		if isEmoji(r) {
+			return -1
+		}
+		return r
+	}, s)
+
+	s = strings.Join(strings.Fields(s), " ")
+	return indent + spaceBeforePunct.ReplaceAllString(s, "$1$2")
+}
+
+var spaceBeforePunct = regexp.MustCompile(` ([.,;:!?])(\s|$)`)
+
+func isEmoji(r rune) bool {
+	switch {
+	case r >= 0x1F000 && r <= 0x1FAFF, // pictographs, emoticons, transport, flags
+		r >= 0x2600 && r <= 0x27BF, // misc symbols and dingbats
+		r >= 0x2B00 && r <= 0x2BFF, // arrows and stars such as ⭐
+		r >= 0x1F1E6 && r <= 0x1F1FF,
+		r == 0x200D, r == 0xFE0F, r == 0x20E3, // joiners and variation selectors
+		r == 0x2139, r == 0x2122, r == 0x3030, r == 0x303D:
+		return true
+	}
+	return false
+}
+
+// WrapLine breaks a line at spaces so that each part fits in width, keeping
+// inline code spans and URLs whole and indenting continuations of list
+// items.
+func WrapLine(line string, width int) []string {
+	if utf8.RuneCountInString(line) <= width {
+		return []string{line}
+	}
+
+	indent := listPrefix.FindString(line)
+	if indent == "" {
+		indent = line[:len(line)-len(strings.TrimLeft(line, " "))]
+	}
+	continuation := strings.Repeat(" ", utf8.RuneCountInString(indent))
+
+	var lines []string
+	current, empty := indent, true
+	for _, word := range codeAwareFields(line[len(indent):]) {
+		switch {
+		case empty:
+			current += word
+		case utf8.RuneCountInString(current)+1+utf8.RuneCountInString(word) > width:
+			lines = append(lines, current)
+			current = continuation + word
+		default:
+			current += " " + word
+		}
+		empty = false
+	}
+	return append(lines, current)
+}
+
+var listPrefix = regexp.MustCompile(`^\s*(?:[-*+•]|\d+[.)])\s+`)
+
+// codeAwareFields splits s at spaces outside of `code spans`.
+func codeAwareFields(s string) []string {
+	var fields []string
+	var b strings.Builder
+	inCode := false
+	for _, r := range s {
+		switch {
+		case r == '`':
+			inCode = !inCode
+			b.WriteRune(r)
+		case unicode.IsSpace(r) && !inCode:
+			if b.Len() > 0 {
+				fields = append(fields, b.String())
+				b.Reset()
+			}
+		default:
+			b.WriteRune(r)
+		}
+	}
+	if b.Len() > 0 {
+		fields = append(fields, b.String())
+	}
+	return fields
+}
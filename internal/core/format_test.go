@@ -0,0 +1,117 @@
+package core
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWrapLine(t *testing.T) {
+	tests := []struct {
+		name  string
+		line  string
+		width int
+		want  []string
+	}{
+		{
+			name:  "fits",
+			line:  "Retry failed requests",
+			width: 21,
+			want:  []string{"Retry failed requests"},
+		},
+		{
+			name:  "at the limit",
+			line:  "Retry failed requests with backoff",
+			width: 21,
+			want:  []string{"Retry failed requests", "with backoff"},
+		},
+		{
+			name:  "bullet",
+			line:  "- Retry failed requests with backoff",
+			width: 20,
+			want:  []string{"- Retry failed", "  requests with", "  backoff"},
+		},
+		{
+			name:  "numbered item",
+			line:  "10. Retry failed requests",
+			width: 16,
+			want:  []string{"10. Retry failed", "    requests"},
+		},
+		{
+			name:  "indentation",
+			line:  "  Retry failed requests",
+			width: 16,
+			want:  []string{"  Retry failed", "  requests"},
+		},
+		{
+			name:  "long URL",
+			line:  "See https://example.com/a/very/long/path/to/the/issue for details",
+			width: 20,
+			want:  []string{"See", "https://example.com/a/very/long/path/to/the/issue", "for details"},
+		},
+		{
+			name:  "code span",
+			line:  "Run `go test ./...` before pushing",
+			width: 15,
+			want:  []string{"Run", "`go test ./...`", "before pushing"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := WrapLine(tt.line, tt.width); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("WrapLine(%q, %d) = %q, want %q", tt.line, tt.width, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatDescription(t *testing.T) {
+	text := `
+		* Retry failed requests with an exponential backoff
+		+ Log every retry
+
+		` + "```" + `
+		if err := client.Do(request); err != nil && retries < maxRetries {
+		` + "```" + `
+
+		    indented code stays as long as it is, however long that may be`
+	want := strings.Join([]string{
+		"- Retry failed requests with an",
+		"  exponential backoff",
+		"- Log every retry",
+		"",
+		"```",
+		"if err := client.Do(request); err != nil && retries < maxRetries {",
+		"```",
+		"",
+		"    indented code stays as long as it is, however long that may be",
+	}, "\n")
+	if got := FormatDescription(text, FormatOptions{Width: 32}); got != want {
+		t.Errorf("FormatDescription() =\n%s\nwant\n%s", got, want)
+	}
+
+	long := "Retry failed requests with an exponential backoff"
+	if got := FormatDescription(long, FormatOptions{Width: -1}); got != long {
+		t.Errorf("FormatDescription() with a negative width = %q, want it unwrapped", got)
+	}
+}
+
+func TestFormatCommitMessageTrailers(t *testing.T) {
+	trailer := Trailer{Key: "Co-authored-by", Value: "A very long name of a contributor <contributor@example.com>"}
+	commit := &CommitMessage{
+		Title:    "Add  retries",
+		Message:  "Retry failed requests with an exponential backoff",
+		Trailers: []Trailer{trailer},
+	}
+	FormatCommitMessage(commit, FormatOptions{Width: 30})
+
+	if commit.Title != "Add retries" {
+		t.Errorf("Title = %q, want %q", commit.Title, "Add retries")
+	}
+	if commit.Message != "Retry failed requests with an\nexponential backoff" {
+		t.Errorf("Message = %q, want it wrapped at 30", commit.Message)
+	}
+	if len(commit.Trailers) != 1 || commit.Trailers[0] != trailer {
+		t.Errorf("Trailers = %v, want %v unwrapped", commit.Trailers, trailer)
+	}
+}
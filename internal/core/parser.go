@@ -1,8 +1,11 @@
 package core
 
 import (
+	"encoding/json"
 	"encoding/xml"
 	"fmt"
+	"reflect"
+	"regexp"
 	"strings"
 )
 
@@ -13,24 +16,278 @@ type xmlCommit struct {
 }
 
 func parseCommitMessage(xmlContent string) (*CommitMessage, error) {
-	// Clean up the XML content
-	xmlContent = strings.TrimSpace(xmlContent)
-	if !strings.HasPrefix(xmlContent, "<commit>") {
-		// Try to find the commit tag
-		start := strings.Index(xmlContent, "<commit>")
-		if start == -1 {
-			return nil, fmt.Errorf("invalid XML format: missing <commit> tag")
-		}
-		xmlContent = xmlContent[start:]
+	var commit xmlCommit
+	if err := unmarshalXML(xmlContent, "commit", &commit); err != nil {
+		return nil, err
 	}
+	return newCommitMessage(commit.Title, commit.Description)
+}
 
-	var commit xmlCommit
-	if err := xml.Unmarshal([]byte(xmlContent), &commit); err != nil {
-		return nil, fmt.Errorf("failed to parse XML: %v", err)
+type jsonCommit struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+// parseCommitJSON reads a structured output response, tolerating code
+// fences and prose around the object.
+func parseCommitJSON(content string) (*CommitMessage, error) {
+	start, end := strings.Index(content, "{"), strings.LastIndex(content, "}")
+	if start == -1 || end < start {
+		return nil, fmt.Errorf("invalid JSON format: missing object")
+	}
+
+	var commit jsonCommit
+	if err := json.Unmarshal([]byte(content[start:end+1]), &commit); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %v", err)
+	}
+	return newCommitMessage(commit.Title, commit.Description)
+}
+
+func newCommitMessage(title, description string) (*CommitMessage, error) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return nil, fmt.Errorf("commit message has no title")
 	}
 
+	message, trailers := splitTrailers(strings.TrimSpace(description))
 	return &CommitMessage{
-		Title:   strings.TrimSpace(commit.Title),
-		Message: strings.TrimSpace(commit.Description),
+		Title:    title,
+		Message:  message,
+		Trailers: trailers,
+	}, nil
+}
+
+// extractElement cuts the first <tag>...</tag> element out of a response,
+// dropping any prose the model wrapped around it.
+func extractElement(content, tag string) (string, error) {
+	content = strings.TrimSpace(content)
+	start := strings.Index(content, "<"+tag+">")
+	if start == -1 {
+		return "", fmt.Errorf("invalid XML format: missing <%s> tag", tag)
+	}
+	content = content[start:]
+	if end := strings.LastIndex(content, "</"+tag+">"); end != -1 {
+		content = content[:end+len("</"+tag+">")]
+	} else {
+		// A truncated element may still be followed by a closing code fence
+		content = strings.TrimSuffix(strings.TrimSpace(content), "```")
+	}
+	return content, nil
+}
+
+// unmarshalXML decodes the <tag> element of a response. Models often
+// forget to escape "&" and "<" in free text or stop before the closing
+// tag, so when strict decoding fails the element is repaired and decoded
+// again.
+func unmarshalXML(content, tag string, v any) error {
+	content, err := extractElement(content, tag)
+	if err != nil {
+		return err
+	}
+
+	err = xml.Unmarshal([]byte(content), v)
+	if err == nil {
+		return nil
+	}
+	tags := map[string]bool{tag: true}
+	xmlTags(reflect.TypeOf(v), tags)
+	if repairErr := xml.Unmarshal([]byte(repairXML(content, tags)), v); repairErr != nil {
+		return fmt.Errorf("failed to parse XML: %v", err)
+	}
+	return nil
+}
+
+var entity = regexp.MustCompile(`^&(?:[A-Za-z]+|#[0-9]+|#x[0-9A-Fa-f]+);`)
+
+// repairXML escapes "&" and "<" that don't start an entity, a CDATA
+// section or one of the given tags, and closes truncated elements, so text
+// such as "Vec<T>" or "R&D" survives.
+func repairXML(content string, tags map[string]bool) string {
+	var b strings.Builder
+	for i := 0; i < len(content); i++ {
+		rest := content[i:]
+		switch {
+		case strings.HasPrefix(rest, "<![CDATA["):
+			end := strings.Index(rest, "]]>")
+			if end == -1 {
+				// Unterminated CDATA runs to the end
+				b.WriteString(rest)
+				b.WriteString("]]>")
+				i = len(content)
+				continue
+			}
+			b.WriteString(rest[:end+len("]]>")])
+			i += end + len("]]>") - 1
+		case rest[0] == '<' && isTag(rest, tags):
+			b.WriteByte('<')
+		case rest[0] == '<':
+			b.WriteString("&lt;")
+		case rest[0] == '&' && !entity.MatchString(rest):
+			b.WriteString("&amp;")
+		default:
+			b.WriteByte(rest[0])
+		}
+	}
+
+	return closeTags(strings.TrimSpace(b.String()), tags)
+}
+
+var anyTag = regexp.MustCompile(`<(/?)([A-Za-z_][\w.-]*)[^>]*?(/?)>`)
+
+// closeTags appends the closing tags of elements left open by a truncated
+// response.
+func closeTags(content string, tags map[string]bool) string {
+	var open []string
+	for _, m := range anyTag.FindAllStringSubmatch(content, -1) {
+		name := m[2]
+		switch {
+		case !tags[name] || m[3] == "/":
+		case m[1] == "/":
+			if len(open) > 0 && open[len(open)-1] == name {
+				open = open[:len(open)-1]
+			}
+		default:
+			open = append(open, name)
+		}
+	}
+	for i := len(open) - 1; i >= 0; i-- {
+		content += "</" + open[i] + ">"
+	}
+	return content
+}
+
+// xmlTags collects the element names used in the xml struct tags of t.
+func xmlTags(t reflect.Type, tags map[string]bool) {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, flags, _ := strings.Cut(f.Tag.Get("xml"), ",")
+		if !isElementField(flags) {
+			continue
+		}
+		if f.Name == "XMLName" {
+			if name != "" {
+				tags[name] = true
+			}
+			continue
+		}
+		if name != "" && name != "-" {
+			tags[name] = true
+		}
+		xmlTags(f.Type, tags)
+	}
+}
+
+// isElementField reports whether the flags of an xml struct tag describe
+// an element, rather than an attribute or the text of the parent.
+func isElementField(flags string) bool {
+	for _, flag := range strings.Split(flags, ",") {
+		switch flag {
+		case "attr", "chardata", "innerxml":
+			return false
+		}
+	}
+	return true
+}
+
+// isTag reports whether s starts with an opening or closing tag of one of
+// the given names.
+func isTag(s string, tags map[string]bool) bool {
+	name := strings.TrimPrefix(s[1:], "/")
+	end := strings.IndexAny(name, " \t\n/>")
+	if end <= 0 {
+		return false
+	}
+	return tags[name[:end]]
+}
+
+type xmlSplitPlan struct {
+	XMLName xml.Name `xml:"groups"`
+	Groups  []struct {
+		Name  string   `xml:"name"`
+		Files []string `xml:"file"`
+	} `xml:"group"`
+}
+
+func parseSplitPlan(xmlContent string) ([]CommitGroup, error) {
+	var plan xmlSplitPlan
+	if err := unmarshalXML(xmlContent, "groups", &plan); err != nil {
+		return nil, err
+	}
+
+	groups := make([]CommitGroup, 0, len(plan.Groups))
+	for _, g := range plan.Groups {
+		group := CommitGroup{Name: strings.TrimSpace(g.Name)}
+		for _, f := range g.Files {
+			if f = strings.TrimSpace(f); f != "" {
+				group.Files = append(group.Files, f)
+			}
+		}
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+type xmlPullRequest struct {
+	XMLName xml.Name `xml:"pr"`
+	Title   string   `xml:"title"`
+	Body    string   `xml:"body"`
+}
+
+func parsePullRequest(xmlContent string) (*PullRequest, error) {
+	var pr xmlPullRequest
+	if err := unmarshalXML(xmlContent, "pr", &pr); err != nil {
+		return nil, err
+	}
+
+	return &PullRequest{
+		Title: strings.TrimSpace(pr.Title),
+		Body:  strings.TrimSpace(pr.Body),
 	}, nil
 }
+
+type xmlChanges struct {
+	XMLName xml.Name `xml:"changes"`
+	Changes []struct {
+		ID   int    `xml:"id,attr"`
+		Type string `xml:",chardata"`
+	} `xml:"change"`
+}
+
+func parseChangeTypes(xmlContent string) (map[int]ChangeType, error) {
+	var changes xmlChanges
+	if err := unmarshalXML(xmlContent, "changes", &changes); err != nil {
+		return nil, err
+	}
+
+	types := make(map[int]ChangeType, len(changes.Changes))
+	for _, ch := range changes.Changes {
+		if t, ok := parseChangeType(ch.Type); ok {
+			types[ch.ID] = t
+		}
+	}
+	return types, nil
+}
+
+type branchSuggestion struct {
+	Type        string `xml:"type"`
+	Description string `xml:"description"`
+}
+
+type xmlBranches struct {
+	XMLName  xml.Name           `xml:"branches"`
+	Branches []branchSuggestion `xml:"branch"`
+}
+
+func parseBranchSuggestions(xmlContent string) ([]branchSuggestion, error) {
+	var branches xmlBranches
+	if err := unmarshalXML(xmlContent, "branches", &branches); err != nil {
+		return nil, err
+	}
+	return branches.Branches, nil
+}
@@ -0,0 +1,101 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCommitMessage(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		title   string
+		message string
+	}{
+		{
+			name:    "well formed",
+			content: "<commit><title>Add retries</title><description>Retry failed requests.</description></commit>",
+			title:   "Add retries",
+			message: "Retry failed requests.",
+		},
+		{
+			name:    "text around the root",
+			content: "Here is the commit message:\n<commit><title>Add retries</title><description>Retry failed requests.</description></commit>\nLet me know if it needs changes.",
+			title:   "Add retries",
+			message: "Retry failed requests.",
+		},
+		{
+			name:    "code fences",
+			content: "```xml\n<commit>\n<title>Add retries</title>\n<description>Retry failed requests.</description>\n</commit>\n```",
+			title:   "Add retries",
+			message: "Retry failed requests.",
+		},
+		{
+			name:    "unclosed tags",
+			content: "<commit><title>Add retries</title><description>Retry failed requests.",
+			title:   "Add retries",
+			message: "Retry failed requests.",
+		},
+		{
+			name:    "unclosed tags in code fences",
+			content: "```xml\n<commit><title>Add retries</title><description>Retry failed requests.\n```",
+			title:   "Add retries",
+			message: "Retry failed requests.",
+		},
+		{
+			name:    "unescaped characters",
+			content: "<commit><title>Return Vec<T> from R&D parser</title><description>Use a < b && c &amp; d, keep &#39;quotes&#39;.</description></commit>",
+			title:   "Return Vec<T> from R&D parser",
+			message: "Use a < b && c & d, keep 'quotes'.",
+		},
+		{
+			name:    "CDATA",
+			content: "<commit><title>Add retries</title><description><![CDATA[if a < b && c {}]]></description></commit>",
+			title:   "Add retries",
+			message: "if a < b && c {}",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commit, err := parseCommitMessage(tt.content)
+			if err != nil {
+				t.Fatalf("parseCommitMessage() error = %v", err)
+			}
+			if commit.Title != tt.title || commit.Message != tt.message {
+				t.Errorf("parseCommitMessage() = %q, %q, want %q, %q", commit.Title, commit.Message, tt.title, tt.message)
+			}
+		})
+	}
+}
+
+func TestParseCommitMessageInvalid(t *testing.T) {
+	for _, content := range []string{
+		"I couldn't find any changes.",
+		"<commit><description>No title</description></commit>",
+	} {
+		if commit, err := parseCommitMessage(content); err == nil {
+			t.Errorf("parseCommitMessage(%q) = %+v, want an error", content, commit)
+		}
+	}
+}
+
+func TestXMLTagsSkipsAttributes(t *testing.T) {
+	tags := map[string]bool{}
+	xmlTags(reflect.TypeOf(&xmlChanges{}), tags)
+	want := map[string]bool{"changes": true, "change": true}
+	if !reflect.DeepEqual(tags, want) {
+		t.Errorf("xmlTags() = %v, want %v", tags, want)
+	}
+}
+
+func TestParseChangeTypesStrayTag(t *testing.T) {
+	content := `<changes><change id="1">Fixed</change><change id="2">Added <id> lookup</change><change id="3">Removed</change>`
+	types, err := parseChangeTypes(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[int]ChangeType{1: Fixed, 3: Removed}
+	if !reflect.DeepEqual(types, want) {
+		t.Errorf("parseChangeTypes() = %v, want %v", types, want)
+	}
+}
@@ -0,0 +1,66 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrEmptyLog = errors.New("no commits to describe")
+
+type PullRequest struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
+
+type PROptions struct {
+	Branch   string
+	Base     string
+	Log      string
+	Stat     string
+	Diffs    string
+	Template string
+	Subject  string
+}
+
+func (c *Core) GeneratePR(ctx context.Context, opts PROptions) (*PullRequest, error) {
+	if strings.TrimSpace(opts.Log) == "" {
+		return nil, fmt.Errorf("invalid options: %w", ErrEmptyLog)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Branch %s is going to be merged into %s.\n\n", opts.Branch, opts.Base)
+	fmt.Fprintf(&b, "Commits:\n\n%s\n\n", opts.Log)
+	if opts.Template != "" {
+		fmt.Fprintf(&b, "Pull request template:\n\n%s\n\n", opts.Template)
+	}
+	if opts.Subject != "" {
+		fmt.Fprintf(&b, "Please focus on the following subject in your pull request: %s\n\n", opts.Subject)
+	}
+	fmt.Fprintf(&b, "Diff stat:\n\n%s\n\nGit diff:\n\n%s\n\n", opts.Stat, opts.Diffs)
+	b.WriteString("Based on this information, generate a pull request title and description in XML format:")
+
+	sys, err := RenderPrompt(PromptPR, PromptData{Subject: opts.Subject})
+	if err != nil {
+		return nil, err
+	}
+
+	defer c.measure()()
+
+	xmlContent, err := c.client.Complete(ctx, sys, b.String())
+	if err != nil {
+		return nil, fmt.Errorf("LLM client failed: %w", err)
+	}
+
+	var pr *PullRequest
+	err = c.withRepair(ctx, sys, xmlContent, func(content string) (err error) {
+		pr, err = parsePullRequest(content)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pr, nil
+}
@@ -1,20 +1,104 @@
 package core
 
-const SystemPrompt = `You are an AI assistant that helps developers write better commit messages. Your task is to analyze the git status and diffs, and generate a descriptive and informative commit message that follows best practices.
-
-Please follow these guidelines:
-• Keep the title concise (max 72 characters) but descriptive
-• Use the imperative mood ("Add feature" not "Added feature")
-• Start with a capital letter
-• Don't end the title with a period
-• Provide a detailed description when the changes are complex
-• Break down the description into bullet points for multiple changes
-• Reference any relevant issue numbers
-
-Format your response in XML with the following structure:
-<commit>
-  <title>Your title here</title>
-  <description>
-    Your detailed description here
-  </description>
-</commit>`
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// Prompt names, each rendered from "<name>.tmpl".
+const (
+	PromptCommit    = "commit"
+	PromptSplit     = "split"
+	PromptPR        = "pr"
+	PromptChangelog = "changelog"
+	PromptBranch    = "branch"
+)
+
+// PromptNames lists every prompt that can be overridden.
+var PromptNames = []string{PromptCommit, PromptSplit, PromptPR, PromptChangelog, PromptBranch}
+
+//go:embed prompts/*.tmpl
+var builtinPrompts embed.FS
+
+// PromptDirs are searched in order for "<name>.tmpl" files overriding the
+// built-in prompts, e.g. the repository's before the user's.
+var PromptDirs []string
+
+// PromptData are the variables available to prompt templates. The
+// built-in commit prompt doesn't use Branch, Subject and Files, which the
+// request already carries; they are there for overrides.
+type PromptData struct {
+	Branch   string
+	Subject  string
+	Language string
+	// Style is "gitmoji", "conventional" or "plain".
+	Style         string
+	RecentCommits []string
+	Files         []string
+	Tickets       []string
+	// Format is the output format, OutputXML or OutputJSON.
+	Format string
+}
+
+var promptFuncs = template.FuncMap{
+	"join": strings.Join,
+}
+
+// RenderPrompt renders the named system prompt.
+func RenderPrompt(name string, data PromptData) (string, error) {
+	content, source, err := loadPrompt(name)
+	if err != nil {
+		return "", err
+	}
+	tmpl, err := template.New(name).Funcs(promptFuncs).Parse(content)
+	if err != nil {
+		return "", fmt.Errorf("invalid prompt %s: %w", source, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render prompt %s: %w", source, err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// PromptSource returns the file the named prompt is read from, or
+// "built-in".
+func PromptSource(name string) string {
+	_, source, _ := loadPrompt(name)
+	return source
+}
+
+func loadPrompt(name string) (string, string, error) {
+	for _, dir := range PromptDirs {
+		path := filepath.Join(dir, name+".tmpl")
+		content, err := os.ReadFile(path)
+		if err == nil {
+			return string(content), path, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", path, err
+		}
+	}
+
+	content, err := builtinPrompts.ReadFile("prompts/" + name + ".tmpl")
+	if err != nil {
+		return "", "", fmt.Errorf("unknown prompt %q", name)
+	}
+	return string(content), "built-in", nil
+}
+
+const RepairPrompt = `Your previous response could not be parsed: %v
+
+Reply again with the same content, corrected to match exactly the format requested in the instructions, without any other text.
+
+Previous response:
+
+%s`
@@ -0,0 +1,89 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// usePromptDirs sets PromptDirs to a repository and a user directory for
+// the duration of the test.
+func usePromptDirs(t *testing.T) (repo, user string) {
+	t.Helper()
+	repo, user = t.TempDir(), t.TempDir()
+	previous := PromptDirs
+	PromptDirs = []string{repo, user}
+	t.Cleanup(func() { PromptDirs = previous })
+	return repo, user
+}
+
+func writePrompt(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name+".tmpl")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRenderPromptOverrides(t *testing.T) {
+	repo, user := usePromptDirs(t)
+	data := PromptData{Branch: "feature/retries", Subject: "CR-22", Files: []string{"a.go", "b.go"}, Format: OutputXML}
+
+	builtin, err := RenderPrompt(PromptCommit, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(builtin, "<commit>") || PromptSource(PromptCommit) != "built-in" {
+		t.Errorf("RenderPrompt() without overrides = %q from %s, want the built-in prompt", builtin, PromptSource(PromptCommit))
+	}
+
+	userPath := writePrompt(t, user, PromptCommit, "User prompt for {{.Branch}}")
+	if got, err := RenderPrompt(PromptCommit, data); err != nil || got != "User prompt for feature/retries" {
+		t.Errorf("RenderPrompt() = %q, %v, want the user's prompt", got, err)
+	}
+	if source := PromptSource(PromptCommit); source != userPath {
+		t.Errorf("PromptSource() = %s, want %s", source, userPath)
+	}
+
+	repoPath := writePrompt(t, repo, PromptCommit, "Repository prompt for {{.Subject}}: {{join .Files \", \"}}")
+	if got, err := RenderPrompt(PromptCommit, data); err != nil || got != "Repository prompt for CR-22: a.go, b.go" {
+		t.Errorf("RenderPrompt() = %q, %v, want the repository's prompt", got, err)
+	}
+	if source := PromptSource(PromptCommit); source != repoPath {
+		t.Errorf("PromptSource() = %s, want %s", source, repoPath)
+	}
+
+	// Other prompts are still built in
+	if source := PromptSource(PromptPR); source != "built-in" {
+		t.Errorf("PromptSource() of the pr prompt = %s, want built-in", source)
+	}
+}
+
+func TestRenderPromptBrokenOverride(t *testing.T) {
+	for name, content := range map[string]string{
+		"syntax":  "Prompt for {{.Branch",
+		"unknown": "Prompt for {{.Repository}}",
+	} {
+		t.Run(name, func(t *testing.T) {
+			repo, _ := usePromptDirs(t)
+			path := writePrompt(t, repo, PromptCommit, content)
+
+			got, err := RenderPrompt(PromptCommit, PromptData{Format: OutputXML})
+			if err == nil {
+				t.Fatalf("RenderPrompt() = %q, want an error", got)
+			}
+			if !strings.Contains(err.Error(), path) {
+				t.Errorf("RenderPrompt() error = %v, want it to name %s", err, path)
+			}
+		})
+	}
+}
+
+func TestRenderPromptUnknown(t *testing.T) {
+	usePromptDirs(t)
+	if _, err := RenderPrompt("release", PromptData{}); err == nil {
+		t.Error("RenderPrompt() of an unknown prompt has no error")
+	}
+}
@@ -0,0 +1,189 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// RedactedPlaceholder replaces secrets in diffs sent to the provider.
+const RedactedPlaceholder = "[REDACTED]"
+
+// Secret is a possible secret found in a diff. The value itself is never
+// kept.
+type Secret struct {
+	Kind string
+	File string
+}
+
+func (s Secret) String() string {
+	if s.File == "" {
+		return s.Kind
+	}
+	return fmt.Sprintf("%s in %s", s.Kind, s.File)
+}
+
+type secretPattern struct {
+	kind string
+	re   *regexp.Regexp
+	// group is the submatch holding the secret, 0 for the whole match.
+	group int
+	// skip rejects matches that aren't secrets, given the submatches.
+	skip func(sub []string) bool
+}
+
+var secretPatterns = []secretPattern{
+	{kind: "AWS access key", re: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{kind: "AWS secret key", re: regexp.MustCompile(`(?i)aws.{0,20}(?:secret|key).{0,5}[=:]\s*["']?([A-Za-z0-9/+=]{40})\b`), group: 1},
+	{kind: "GitHub token", re: regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`)},
+	{kind: "Slack token", re: regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}\b`)},
+	{kind: "API key", re: regexp.MustCompile(`\bsk-(?:ant-)?[A-Za-z0-9_-]{20,}\b`)},
+	{kind: "JWT", re: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}\b`)},
+	{
+		kind:  "credential",
+		re:    regexp.MustCompile("(?i)(?:password|passwd|secret|token|api[_-]?key)[\"']?\\s*(?::=|[:=])\\s*([\"'`]?)([^\\s\"'`,;=][^\\s\"'`,;]{7,})"),
+		group: 2,
+		skip:  isCodeValue,
+	},
+}
+
+var (
+	// codeSelector matches selectors such as cfg.APIKey.
+	codeSelector = regexp.MustCompile(`^[A-Za-z_]\w*(?:\.[A-Za-z_]\w*)+$`)
+	// codeIdentifier matches variable names. Names with digits are left to
+	// the secret check, unquoted config values such as hunter2hunter2 look
+	// the same.
+	codeIdentifier = regexp.MustCompile(`^[A-Za-z_]+$`)
+)
+
+// isCodeValue reports whether an unquoted credential value is code, like a
+// variable, a field or a call, rather than a literal secret.
+func isCodeValue(sub []string) bool {
+	quote, value := sub[1], sub[2]
+	if quote != "" {
+		return false
+	}
+	return strings.ContainsAny(value, "()[]{}") || codeSelector.MatchString(value) || codeIdentifier.MatchString(value)
+}
+
+var (
+	privateKeyBegin = regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY( BLOCK)?-----`)
+	privateKeyEnd   = regexp.MustCompile(`-----END [A-Z ]*PRIVATE KEY( BLOCK)?-----`)
+	quotedString    = regexp.MustCompile("[\"'`]([A-Za-z0-9+/=_-]{20,})[\"'`]")
+)
+
+// minSecretEntropy is the Shannon entropy in bits per character above which
+// a quoted token looks random enough to be a key. Hex hashes stay below it.
+const minSecretEntropy = 4.0
+
+// RedactSecrets masks likely secrets in a diff and reports what was
+// masked. It understands both the per-file "Diff for <file>:" sections and
+// plain "git diff" output.
+func RedactSecrets(diff string) (string, []Secret) {
+	var secrets []Secret
+	var file string
+	var inKey bool
+
+	lines := strings.Split(diff, "\n")
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "Diff for ") && strings.HasSuffix(line, ":"):
+			file = strings.TrimSuffix(strings.TrimPrefix(line, "Diff for "), ":")
+			inKey = false
+			continue
+		case strings.HasPrefix(line, "diff --git "):
+			if i := strings.LastIndex(line, " b/"); i != -1 {
+				file = line[i+len(" b/"):]
+			}
+			inKey = false
+			continue
+		case strings.HasPrefix(line, "+++ b/"):
+			file = strings.TrimPrefix(line, "+++ b/")
+			continue
+		case strings.HasPrefix(line, "--- a/"), line == "--- /dev/null", line == "+++ /dev/null",
+			strings.HasPrefix(line, "index "),
+			strings.HasPrefix(line, "@@"), line == "":
+			continue
+		}
+
+		// Keep the diff marker of added, removed and context lines
+		sign, content := "", line
+		if strings.ContainsAny(line[:1], "+- ") {
+			sign, content = line[:1], line[1:]
+		}
+
+		switch {
+		case inKey:
+			if privateKeyEnd.MatchString(content) {
+				inKey = false
+			}
+			lines[i] = sign + RedactedPlaceholder
+			continue
+		case privateKeyBegin.MatchString(content):
+			secrets = append(secrets, Secret{Kind: "private key", File: file})
+			inKey = !privateKeyEnd.MatchString(content)
+			lines[i] = sign + RedactedPlaceholder
+			continue
+		case isEnvFile(file):
+			if key, value, ok := strings.Cut(content, "="); ok && strings.TrimSpace(value) != "" {
+				secrets = append(secrets, Secret{Kind: "env value", File: file})
+				lines[i] = sign + key + "=" + RedactedPlaceholder
+			}
+			continue
+		}
+
+		for _, p := range secretPatterns {
+			content = p.re.ReplaceAllStringFunc(content, func(match string) string {
+				sub := p.re.FindStringSubmatch(match)
+				if p.skip != nil && p.skip(sub) {
+					return match
+				}
+				secrets = append(secrets, Secret{Kind: p.kind, File: file})
+				if p.group == 0 {
+					return RedactedPlaceholder
+				}
+				return strings.Replace(match, sub[p.group], RedactedPlaceholder, 1)
+			})
+		}
+		content = quotedString.ReplaceAllStringFunc(content, func(match string) string {
+			token := match[1 : len(match)-1]
+			if entropy(token) < minSecretEntropy {
+				return match
+			}
+			secrets = append(secrets, Secret{Kind: "high-entropy string", File: file})
+			return match[:1] + RedactedPlaceholder + match[len(match)-1:]
+		})
+		lines[i] = sign + content
+	}
+	return strings.Join(lines, "\n"), secrets
+}
+
+// isEnvFile matches dotenv files, except the usual committed templates.
+func isEnvFile(file string) bool {
+	name := path.Base(file)
+	if name != ".env" && !strings.HasPrefix(name, ".env.") {
+		return false
+	}
+	switch path.Ext(name) {
+	case ".example", ".sample", ".template", ".dist":
+		return false
+	}
+	return true
+}
+
+// entropy returns the Shannon entropy of s in bits per character.
+func entropy(s string) float64 {
+	counts := make(map[rune]int)
+	for _, r := range s {
+		counts[r]++
+	}
+	var h float64
+	n := float64(len(s))
+	for _, c := range counts {
+		p := float64(c) / n
+		h -= p * math.Log2(p)
+	}
+	return h
+}
@@ -0,0 +1,74 @@
+package core
+
+import (
+	"testing"
+)
+
+func TestRedactSecretsCredentials(t *testing.T) {
+	tests := []struct {
+		name   string
+		line   string
+		want   string
+		secret bool
+	}{
+		{
+			name:   "quoted password",
+			line:   `+password = "hunter2hunter2"`,
+			want:   `+password = "[REDACTED]"`,
+			secret: true,
+		},
+		{
+			name:   "quoted api key in struct literal",
+			line:   `+	Config{APIKey: "abcd1234efgh"}`,
+			want:   `+	Config{APIKey: "[REDACTED]"}`,
+			secret: true,
+		},
+		{
+			name:   "short var declaration",
+			line:   `+token := 'supersecretvalue'`,
+			want:   `+token := '[REDACTED]'`,
+			secret: true,
+		},
+		{
+			name:   "unquoted yaml value",
+			line:   `+  password: hunter2hunter2`,
+			want:   `+  password: [REDACTED]`,
+			secret: true,
+		},
+		{
+			name: "call",
+			line: `+token = os.Getenv("GITHUB_TOKEN")`,
+		},
+		{
+			name: "selectors",
+			line: `+	Config{APIKey: cfg.APIKey, Password: req.Password}`,
+		},
+		{
+			name: "identifier",
+			line: `+	secret := derivedSecret`,
+		},
+		{
+			name: "template reference",
+			line: `+  api_key: ${API_KEY_FROM_ENV}`,
+		},
+		{
+			name: "comparison",
+			line: `+if token == nil {`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := tt.want
+			if want == "" {
+				want = tt.line
+			}
+			got, secrets := RedactSecrets("Diff for main.go:\n" + tt.line)
+			if got != "Diff for main.go:\n"+want {
+				t.Errorf("RedactSecrets(%q) = %q, want %q", tt.line, got, want)
+			}
+			if (len(secrets) > 0) != tt.secret {
+				t.Errorf("RedactSecrets(%q) found %v, want secret %t", tt.line, secrets, tt.secret)
+			}
+		})
+	}
+}
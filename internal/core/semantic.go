@@ -0,0 +1,222 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"sort"
+	"strings"
+)
+
+// SourceChange holds both versions of a changed file. Old is nil for added
+// files and New is nil for deleted ones.
+type SourceChange struct {
+	Path string
+	Old  []byte
+	New  []byte
+}
+
+// Summarizer describes what changed in the API of a source file, for
+// languages where the raw diff hides it.
+type Summarizer interface {
+	// Match reports whether the summarizer understands the file.
+	Match(path string) bool
+	// Summarize returns one line per added, removed or changed declaration.
+	Summarize(change SourceChange) ([]string, error)
+}
+
+var summarizers = []Summarizer{goSummarizer{}}
+
+// RegisterSummarizer adds support for another language. Summarizers are
+// tried in order and the first match wins.
+func RegisterSummarizer(s Summarizer) {
+	summarizers = append(summarizers, s)
+}
+
+// HasSummarizer reports whether any summarizer understands the file.
+func HasSummarizer(path string) bool {
+	return summarizerFor(path) != nil
+}
+
+func summarizerFor(path string) Summarizer {
+	for _, s := range summarizers {
+		if s.Match(path) {
+			return s
+		}
+	}
+	return nil
+}
+
+// SummarizeChanges renders the API level summary of the given files.
+// Files that fail to parse are left to the raw diff.
+func SummarizeChanges(changes []SourceChange) string {
+	var b strings.Builder
+	for _, c := range changes {
+		s := summarizerFor(c.Path)
+		if s == nil {
+			continue
+		}
+		lines, err := s.Summarize(c)
+		if err != nil || len(lines) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "%s:\n", c.Path)
+		for _, line := range lines {
+			fmt.Fprintf(&b, "  %s\n", line)
+		}
+	}
+	return b.String()
+}
+
+// goSummarizer compares the exported declarations of two versions of a Go
+// file.
+type goSummarizer struct{}
+
+func (goSummarizer) Match(path string) bool {
+	return strings.HasSuffix(path, ".go")
+}
+
+func (goSummarizer) Summarize(change SourceChange) ([]string, error) {
+	oldDecls, err := goDecls(change.Path, change.Old)
+	if err != nil {
+		return nil, err
+	}
+	newDecls, err := goDecls(change.Path, change.New)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []string
+	for _, key := range sortedKeys(newDecls) {
+		n := newDecls[key]
+		o, ok := oldDecls[key]
+		switch {
+		case !ok:
+			lines = append(lines, "+ "+n.signature)
+		case o.signature != n.signature:
+			lines = append(lines, fmt.Sprintf("~ %s  (was: %s)", n.signature, o.signature))
+		case o.body != n.body:
+			lines = append(lines, fmt.Sprintf("* %s  (%s)", n.signature, n.changed))
+		}
+	}
+	for _, key := range sortedKeys(oldDecls) {
+		if _, ok := newDecls[key]; !ok {
+			lines = append(lines, "- "+oldDecls[key].signature)
+		}
+	}
+	return lines, nil
+}
+
+type goDecl struct {
+	signature string
+	// body is compared to detect changes that keep the signature, changed
+	// describes them.
+	body    string
+	changed string
+}
+
+// goDecls collects the exported funcs, methods, types, vars and consts of a
+// Go file, keyed by kind and name.
+func goDecls(path string, src []byte) (map[string]goDecl, error) {
+	decls := make(map[string]goDecl)
+	if src == nil {
+		return decls, nil
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, src, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, d := range file.Decls {
+		switch d := d.(type) {
+		case *ast.FuncDecl:
+			if !d.Name.IsExported() {
+				continue
+			}
+			key := "func " + d.Name.Name
+			if d.Recv != nil && len(d.Recv.List) > 0 {
+				key = "method " + render(fset, d.Recv.List[0].Type) + "." + d.Name.Name
+			}
+			body := d.Body
+			d.Body = nil
+			decls[key] = goDecl{signature: render(fset, d), body: render(fset, body), changed: "body changed"}
+			d.Body = body
+
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					if s.Name.IsExported() {
+						decls["type "+s.Name.Name] = goDecl{
+							signature: "type " + s.Name.Name + " " + typeKind(fset, s.Type),
+							body:      render(fset, s),
+							changed:   "definition changed",
+						}
+					}
+				case *ast.ValueSpec:
+					for _, name := range s.Names {
+						if !name.IsExported() {
+							continue
+						}
+						sig := d.Tok.String() + " " + name.Name
+						if s.Type != nil {
+							sig += " " + render(fset, s.Type)
+						}
+						decls[d.Tok.String()+" "+name.Name] = goDecl{signature: sig}
+					}
+				}
+			}
+		}
+	}
+	return decls, nil
+}
+
+// typeKind names the kind of a composite type without its contents, simple
+// types are printed as is.
+func typeKind(fset *token.FileSet, expr ast.Expr) string {
+	switch expr.(type) {
+	case *ast.StructType:
+		return "struct"
+	case *ast.InterfaceType:
+		return "interface"
+	case *ast.FuncType:
+		return "func"
+	case *ast.MapType:
+		return "map"
+	case *ast.ArrayType:
+		return "slice"
+	case *ast.ChanType:
+		return "chan"
+	default:
+		return render(fset, expr)
+	}
+}
+
+// render prints a node on a single line.
+func render(fset *token.FileSet, node any) string {
+	if node == nil {
+		return ""
+	}
+	if b, ok := node.(*ast.BlockStmt); ok && b == nil {
+		return ""
+	}
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fset, node); err != nil {
+		return ""
+	}
+	return strings.Join(strings.Fields(buf.String()), " ")
+}
+
+func sortedKeys(m map[string]goDecl) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,98 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGoSummarizer(t *testing.T) {
+	tests := []struct {
+		name string
+		old  string
+		new  string
+		want []string
+	}{
+		{
+			name: "added func",
+			old:  "package a\n",
+			new:  "package a\n\nfunc Retry(n int) error { return nil }\n",
+			want: []string{"+ func Retry(n int) error"},
+		},
+		{
+			name: "removed func",
+			old:  "package a\n\nfunc Retry(n int) error { return nil }\n",
+			new:  "package a\n",
+			want: []string{"- func Retry(n int) error"},
+		},
+		{
+			name: "changed signature",
+			old:  "package a\n\nfunc Retry(n int) error { return nil }\n",
+			new:  "package a\n\nfunc Retry(ctx context.Context, n int) error { return nil }\n",
+			want: []string{"~ func Retry(ctx context.Context, n int) error  (was: func Retry(n int) error)"},
+		},
+		{
+			name: "changed body",
+			old:  "package a\n\nfunc Retry(n int) error { return nil }\n",
+			new:  "package a\n\nfunc Retry(n int) error { return errors.New(\"no\") }\n",
+			want: []string{"* func Retry(n int) error  (body changed)"},
+		},
+		{
+			name: "methods",
+			old:  "package a\n\nfunc (c *Client) Do() {}\n",
+			new:  "package a\n\nfunc (c *Client) Do() {}\n\nfunc (c *Client) Close() error { return nil }\n",
+			want: []string{"+ func (c *Client) Close() error"},
+		},
+		{
+			name: "types",
+			old:  "package a\n\ntype Options struct{ Retries int }\n\ntype Mode int\n",
+			new:  "package a\n\ntype Options struct{ Retries, Timeout int }\n\ntype Mode string\n",
+			want: []string{"~ type Mode string  (was: type Mode int)", "* type Options struct  (definition changed)"},
+		},
+		{
+			name: "unexported declarations",
+			old:  "package a\n\nfunc retry() {}\n",
+			new:  "package a\n\nfunc retry() { retry() }\n\ntype options struct{}\n",
+		},
+		{
+			name: "added file",
+			new:  "package a\n\nconst Version = \"1.0\"\n\nvar Default Options\n",
+			want: []string{"+ const Version", "+ var Default Options"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			change := SourceChange{Path: "a.go", New: []byte(tt.new)}
+			if tt.old != "" {
+				change.Old = []byte(tt.old)
+			}
+			got, err := goSummarizer{}.Summarize(change)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Summarize() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSummarizeChanges(t *testing.T) {
+	changes := []SourceChange{
+		{Path: "a.go", Old: []byte("package a\n"), New: []byte("package a\n\nfunc Retry() {}\n")},
+		// Unparsable files are left to the raw diff
+		{Path: "b.go", Old: []byte("package b\n"), New: []byte("package b\n\nfunc Broken( {\n")},
+		// Other languages have no summarizer
+		{Path: "c.py", Old: []byte(""), New: []byte("def retry():\n    pass\n")},
+	}
+	want := "a.go:\n  + func Retry()\n"
+	if got := SummarizeChanges(changes); got != want {
+		t.Errorf("SummarizeChanges() = %q, want %q", got, want)
+	}
+
+	if _, err := (goSummarizer{}).Summarize(changes[1]); err == nil {
+		t.Error("Summarize() of an unparsable file has no error")
+	}
+	if HasSummarizer("c.py") || !HasSummarizer("a.go") {
+		t.Error("HasSummarizer() should only match Go files")
+	}
+}
@@ -0,0 +1,95 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrNoFiles = errors.New("no changed files to split")
+
+// CommitGroup is a set of files that should be committed together.
+type CommitGroup struct {
+	Name  string
+	Files []string
+}
+
+type SplitOptions struct {
+	Status string
+	Diffs  string
+	Files  []string
+}
+
+// PlanSplit asks the LLM to group the changed files into logical commits.
+// Files the model leaves out are collected into a trailing group, so the
+// plan always covers every changed file exactly once.
+func (c *Core) PlanSplit(ctx context.Context, opts SplitOptions) ([]CommitGroup, error) {
+	if len(opts.Files) == 0 {
+		return nil, ErrNoFiles
+	}
+
+	prompt := fmt.Sprintf("Changed files:\n\n%s\n\nGit status:\n\n%s\n\nGit diffs:\n\n%s\n\nBased on this information, split the changes into logical commits in XML format:",
+		strings.Join(opts.Files, "\n"), opts.Status, opts.Diffs)
+
+	sys, err := RenderPrompt(PromptSplit, PromptData{Files: opts.Files})
+	if err != nil {
+		return nil, err
+	}
+
+	defer c.measure()()
+
+	xmlContent, err := c.client.Complete(ctx, sys, prompt)
+	if err != nil {
+		return nil, fmt.Errorf("LLM client failed: %w", err)
+	}
+
+	var groups []CommitGroup
+	err = c.withRepair(ctx, sys, xmlContent, func(content string) (err error) {
+		groups, err = parseSplitPlan(content)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return normalizeGroups(groups, opts.Files), nil
+}
+
+// normalizeGroups drops unknown and duplicate files from the model's plan
+// and appends any files it missed as a separate group.
+func normalizeGroups(groups []CommitGroup, files []string) []CommitGroup {
+	known := make(map[string]bool, len(files))
+	for _, f := range files {
+		known[f] = true
+	}
+
+	seen := make(map[string]bool, len(files))
+	var result []CommitGroup
+	for _, g := range groups {
+		var groupFiles []string
+		for _, f := range g.Files {
+			if !known[f] || seen[f] {
+				continue
+			}
+			seen[f] = true
+			groupFiles = append(groupFiles, f)
+		}
+		if len(groupFiles) == 0 {
+			continue
+		}
+		result = append(result, CommitGroup{Name: g.Name, Files: groupFiles})
+	}
+
+	var rest []string
+	for _, f := range files {
+		if !seen[f] {
+			rest = append(rest, f)
+		}
+	}
+	if len(rest) > 0 {
+		result = append(result, CommitGroup{Name: "Remaining changes", Files: rest})
+	}
+
+	return result
+}
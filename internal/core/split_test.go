@@ -0,0 +1,120 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeGroups(t *testing.T) {
+	files := []string{"a.go", "b.go", "c.go", "README.md"}
+	tests := []struct {
+		name   string
+		groups []CommitGroup
+		want   []CommitGroup
+	}{
+		{
+			name: "complete plan",
+			groups: []CommitGroup{
+				{Name: "Add retries", Files: []string{"a.go", "b.go"}},
+				{Name: "Document retries", Files: []string{"c.go", "README.md"}},
+			},
+			want: []CommitGroup{
+				{Name: "Add retries", Files: []string{"a.go", "b.go"}},
+				{Name: "Document retries", Files: []string{"c.go", "README.md"}},
+			},
+		},
+		{
+			name: "unknown and duplicate files",
+			groups: []CommitGroup{
+				{Name: "Add retries", Files: []string{"a.go", "missing.go", "b.go"}},
+				{Name: "Document retries", Files: []string{"b.go", "c.go", "README.md"}},
+			},
+			want: []CommitGroup{
+				{Name: "Add retries", Files: []string{"a.go", "b.go"}},
+				{Name: "Document retries", Files: []string{"c.go", "README.md"}},
+			},
+		},
+		{
+			name: "groups left empty",
+			groups: []CommitGroup{
+				{Name: "Add retries", Files: []string{"a.go", "b.go", "c.go", "README.md"}},
+				{Name: "Nothing", Files: []string{"a.go", "missing.go"}},
+			},
+			want: []CommitGroup{
+				{Name: "Add retries", Files: []string{"a.go", "b.go", "c.go", "README.md"}},
+			},
+		},
+		{
+			name: "missed files",
+			groups: []CommitGroup{
+				{Name: "Add retries", Files: []string{"b.go"}},
+			},
+			want: []CommitGroup{
+				{Name: "Add retries", Files: []string{"b.go"}},
+				{Name: "Remaining changes", Files: []string{"a.go", "c.go", "README.md"}},
+			},
+		},
+		{
+			name: "no groups",
+			want: []CommitGroup{
+				{Name: "Remaining changes", Files: files},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := normalizeGroups(tt.groups, files)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("normalizeGroups() = %+v, want %+v", got, tt.want)
+			}
+			checkEveryFileOnce(t, got, files)
+		})
+	}
+}
+
+// checkEveryFileOnce fails unless every file is in exactly one group.
+func checkEveryFileOnce(t *testing.T, groups []CommitGroup, files []string) {
+	t.Helper()
+	count := make(map[string]int)
+	for _, g := range groups {
+		for _, f := range g.Files {
+			count[f]++
+		}
+	}
+	for _, f := range files {
+		if count[f] != 1 {
+			t.Errorf("%s is in %d groups, want 1", f, count[f])
+		}
+		delete(count, f)
+	}
+	for f := range count {
+		t.Errorf("%s is not a changed file", f)
+	}
+}
+
+func TestPlanSplit(t *testing.T) {
+	files := []string{"a.go", "b.go", "README.md"}
+	response := `<groups>
+  <group><name>Add retries</name><file>a.go</file><file>../outside.go</file></group>
+  <group><name>Test retries</name><file>a.go</file></group>
+</groups>`
+	c := NewCore(&fakeClient{response: response})
+
+	groups, err := c.PlanSplit(context.Background(), SplitOptions{Files: files})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []CommitGroup{
+		{Name: "Add retries", Files: []string{"a.go"}},
+		{Name: "Remaining changes", Files: []string{"b.go", "README.md"}},
+	}
+	if !reflect.DeepEqual(groups, want) {
+		t.Errorf("PlanSplit() = %+v, want %+v", groups, want)
+	}
+
+	if _, err := c.PlanSplit(context.Background(), SplitOptions{}); !errors.Is(err, ErrNoFiles) {
+		t.Errorf("PlanSplit() without files = %v, want ErrNoFiles", err)
+	}
+}
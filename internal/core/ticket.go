@@ -0,0 +1,37 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// DefaultTicketPatterns match JIRA-style keys such as CR-22.
+var DefaultTicketPatterns = []string{`[A-Z][A-Z0-9]+-\d+`}
+
+// ExtractTickets returns the unique ticket IDs matched by the patterns in s.
+// When a pattern has a capture group, the first group is used as the ID.
+func ExtractTickets(s string, patterns []string) ([]string, error) {
+	if len(patterns) == 0 {
+		patterns = DefaultTicketPatterns
+	}
+
+	seen := make(map[string]bool)
+	var tickets []string
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ticket pattern %q: %w", p, err)
+		}
+		for _, m := range re.FindAllStringSubmatch(s, -1) {
+			ticket := m[0]
+			if len(m) > 1 && m[1] != "" {
+				ticket = m[1]
+			}
+			if !seen[ticket] {
+				seen[ticket] = true
+				tickets = append(tickets, ticket)
+			}
+		}
+	}
+	return tickets, nil
+}
@@ -0,0 +1,75 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var trailerLineRe = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9-]*):\s+(\S.*)$`)
+
+// Trailer is a "Key: Value" line at the end of a commit message, such as
+// Signed-off-by or Co-authored-by.
+type Trailer struct {
+	Key   string
+	Value string
+}
+
+func (t Trailer) String() string {
+	return t.Key + ": " + t.Value
+}
+
+// ParseTrailer parses a trailer given as "Key: Value" or "Key=Value".
+func ParseTrailer(s string) (Trailer, error) {
+	sep := strings.IndexAny(s, ":=")
+	if sep <= 0 {
+		return Trailer{}, fmt.Errorf("invalid trailer %q, expected \"Key: Value\"", s)
+	}
+
+	t := Trailer{
+		Key:   strings.TrimSpace(s[:sep]),
+		Value: strings.TrimSpace(s[sep+1:]),
+	}
+	if t.Key == "" || t.Value == "" || strings.ContainsAny(t.Key, " \t") {
+		return Trailer{}, fmt.Errorf("invalid trailer %q, expected \"Key: Value\"", s)
+	}
+	return t, nil
+}
+
+// trailerKeys are the single-word keys accepted as trailers. Like git's
+// interpret-trailers heuristics, other keys need a hyphen, as in
+// Signed-off-by, so a closing "Note: ..." paragraph stays in the body.
+var trailerKeys = []string{"Bug", "Cc", "Closes", "Fixes", "Link", "Ref", "Refs", "Resolves", "See"}
+
+// IsTrailerKey reports whether a "Key: Value" line with this key is taken
+// as a trailer.
+func IsTrailerKey(key string) bool {
+	if strings.Contains(strings.Trim(key, "-"), "-") {
+		return true
+	}
+	for _, k := range trailerKeys {
+		if strings.EqualFold(k, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitTrailers separates a trailing paragraph made only of trailer lines
+// (e.g. "Refs: CR-22") from the rest of the message.
+func splitTrailers(message string) (string, []Trailer) {
+	idx := strings.LastIndex(message, "\n\n")
+	if idx == -1 {
+		return message, nil
+	}
+
+	var trailers []Trailer
+	for _, line := range strings.Split(strings.TrimSpace(message[idx+2:]), "\n") {
+		m := trailerLineRe.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil || !IsTrailerKey(m[1]) {
+			return message, nil
+		}
+		trailers = append(trailers, Trailer{Key: m[1], Value: m[2]})
+	}
+	return strings.TrimSpace(message[:idx]), trailers
+}
@@ -0,0 +1,45 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitTrailers(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  string
+		body     string
+		trailers []Trailer
+	}{
+		{
+			name:     "hyphenated and known keys",
+			message:  "Retry uploads.\n\nSigned-off-by: Jane <jane@example.com>\nRefs: CR-22",
+			body:     "Retry uploads.",
+			trailers: []Trailer{{Key: "Signed-off-by", Value: "Jane <jane@example.com>"}, {Key: "Refs", Value: "CR-22"}},
+		},
+		{
+			name:    "closing note stays in the body",
+			message: "Retry uploads.\n\nNote: this is important",
+			body:    "Retry uploads.\n\nNote: this is important",
+		},
+		{
+			name:    "mixed paragraph stays in the body",
+			message: "Retry uploads.\n\nWarning: slow\nCo-authored-by: Jo <jo@example.com>",
+			body:    "Retry uploads.\n\nWarning: slow\nCo-authored-by: Jo <jo@example.com>",
+		},
+		{
+			name:    "single paragraph",
+			message: "Fixes: the parser",
+			body:    "Fixes: the parser",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, trailers := splitTrailers(tt.message)
+			if body != tt.body || !reflect.DeepEqual(trailers, tt.trailers) {
+				t.Errorf("splitTrailers() = %q, %v, want %q, %v", body, trailers, tt.body, tt.trailers)
+			}
+		})
+	}
+}
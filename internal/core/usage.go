@@ -0,0 +1,85 @@
+package core
+
+import "fmt"
+
+// Usage counts the tokens used by one or more requests to a provider.
+type Usage struct {
+	Provider string
+	Model    string
+	// InputTokens include CachedTokens, the part of the prompt read from the
+	// provider's prompt cache.
+	InputTokens  int
+	OutputTokens int
+	CachedTokens int
+}
+
+// Add returns the sum of two usages of the same model.
+func (u Usage) Add(o Usage) Usage {
+	u.InputTokens += o.InputTokens
+	u.OutputTokens += o.OutputTokens
+	u.CachedTokens += o.CachedTokens
+	return u
+}
+
+// Sub returns the usage since an earlier total.
+func (u Usage) Sub(o Usage) Usage {
+	u.InputTokens -= o.InputTokens
+	u.OutputTokens -= o.OutputTokens
+	u.CachedTokens -= o.CachedTokens
+	return u
+}
+
+// IsZero reports whether no tokens were used.
+func (u Usage) IsZero() bool {
+	return u.InputTokens == 0 && u.OutputTokens == 0
+}
+
+func (u Usage) String() string {
+	s := fmt.Sprintf("%d input, %d output tokens", u.InputTokens, u.OutputTokens)
+	if u.CachedTokens > 0 {
+		s += fmt.Sprintf(", %d cached", u.CachedTokens)
+	}
+	return s
+}
+
+// UsageReporter is implemented by clients that count the tokens of their
+// requests.
+type UsageReporter interface {
+	// Usage returns the total usage of all requests so far.
+	Usage() Usage
+}
+
+// Usage returns the tokens used through this core so far, zero when the
+// client doesn't report usage.
+func (c *Core) Usage() Usage {
+	if r, ok := c.client.(UsageReporter); ok {
+		return r.Usage()
+	}
+	return Usage{}
+}
+
+// Model returns the provider and model the client sends requests to, empty
+// when the client doesn't report usage.
+func (c *Core) Model() (provider, model string) {
+	u := c.Usage()
+	return u.Provider, u.Model
+}
+
+// OnUsage registers a function called with the usage of every operation,
+// e.g. to keep a ledger.
+func (c *Core) OnUsage(f func(Usage)) {
+	c.onUsage = f
+}
+
+// measure starts counting the usage of an operation. The returned function
+// reports the usage since the start to the OnUsage function and returns it.
+func (c *Core) measure() func() Usage {
+	before := c.Usage()
+	return func() Usage {
+		u := c.Usage().Sub(before)
+		if c.onUsage != nil && !u.IsZero() {
+			c.onUsage(u)
+		}
+		return u
+	}
+}
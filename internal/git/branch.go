@@ -0,0 +1,60 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GetCurrentBranch returns the name of the checked out branch.
+func GetCurrentBranch() (string, error) {
+	branch, err := current.CurrentBranch()
+	if err != nil {
+		return "", fmt.Errorf("failed to get current branch: %w", err)
+	}
+	return branch, nil
+}
+
+// GetDefaultBranch guesses the branch pull requests are merged into, using
+// the remote HEAD when available and falling back to main or master.
+func GetDefaultBranch() (string, error) {
+	if err := requireExec("detecting the default branch"); err != nil {
+		return "", err
+	}
+	cmd := command("symbolic-ref", "--short", "refs/remotes/origin/HEAD")
+	if output, err := cmd.Output(); err == nil {
+		return strings.TrimSpace(string(output)), nil
+	}
+
+	for _, branch := range []string{"main", "master"} {
+		if command("rev-parse", "--verify", "--quiet", branch).Run() == nil {
+			return branch, nil
+		}
+	}
+	return "", fmt.Errorf("failed to detect default branch")
+}
+
+// GetMergeBase returns the best common ancestor of two revisions.
+func GetMergeBase(a, b string) (string, error) {
+	if err := requireExec("git merge-base"); err != nil {
+		return "", err
+	}
+	cmd := command("merge-base", a, b)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to find merge base of %s and %s: %w", a, b, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// CreateBranch creates a new branch from HEAD and checks it out.
+func CreateBranch(name string) error {
+	if err := requireExec("git checkout"); err != nil {
+		return err
+	}
+	cmd := command("checkout", "-b", name)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("git checkout failed: %v\nOutput: %s", err, string(output))
+	}
+	return nil
+}
@@ -0,0 +1,311 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ChangeKind tells how a changed file is presented to the model.
+type ChangeKind string
+
+const (
+	KindText      ChangeKind = "text"
+	KindBinary    ChangeKind = "binary"
+	KindLarge     ChangeKind = "large"
+	KindGenerated ChangeKind = "generated"
+)
+
+// FileChange is the classification of one changed file.
+type FileChange struct {
+	Path string
+	Kind ChangeKind
+	// Added and Deleted are line counts, -1 for binary files.
+	Added   int
+	Deleted int
+	// OldSize and NewSize are in bytes, -1 when the file doesn't exist on
+	// that side.
+	OldSize int64
+	NewSize int64
+}
+
+// Summary describes a non-text change in one line, in place of its diff.
+func (c FileChange) Summary() string {
+	switch c.Kind {
+	case KindBinary:
+		return fmt.Sprintf("%s | binary file, %s (content omitted)\n", c.Path, sizeDelta(c.OldSize, c.NewSize))
+	case KindLarge:
+		return fmt.Sprintf("%s | large file, %d insertions(+), %d deletions(-), %s (content omitted)\n", c.Path, c.Added, c.Deleted, sizeDelta(c.OldSize, c.NewSize))
+	default:
+		return fmt.Sprintf("%s | %s file, %d insertions(+), %d deletions(-) (content omitted)\n", c.Path, c.Kind, c.Added, c.Deleted)
+	}
+}
+
+// DiffLimits are the thresholds above which a text file is summarized
+// instead of diffed.
+type DiffLimits struct {
+	// MaxLines is the number of changed lines, added plus deleted.
+	MaxLines int
+	// MaxBytes is the size of the new version of the file.
+	MaxBytes int64
+}
+
+// DefaultDiffLimits are used unless configured otherwise.
+var DefaultDiffLimits = DiffLimits{MaxLines: 1000, MaxBytes: 1 << 20}
+
+// Limits are the diff limits used when classifying changes.
+var Limits = DefaultDiffLimits
+
+// ClassifyChanges classifies the given files using "git diff --numstat",
+// the binary, diff, filter and linguist-generated attributes and the file
+// sizes. Files stored with Git LFS count as binary. Staged compares HEAD
+// with the index, otherwise the index is compared with the working tree.
+func ClassifyChanges(files []string, staged bool) (map[string]FileChange, error) {
+	changes := make(map[string]FileChange, len(files))
+	if len(files) == 0 {
+		return changes, nil
+	}
+	if err := requireExec("classifying changes"); err != nil {
+		return nil, err
+	}
+
+	numstat, err := numstat(files, staged)
+	if err != nil {
+		return nil, err
+	}
+	attrs, err := checkAttrs(files)
+	if err != nil {
+		return nil, err
+	}
+
+	oldRev, newRev := ":", ""
+	if staged {
+		oldRev, newRev = "HEAD:", ":"
+	}
+	oldSizes, err := blobSizes(files, oldRev)
+	if err != nil {
+		return nil, err
+	}
+	newSizes := make(map[string]int64, len(files))
+	if newRev != "" {
+		if newSizes, err = blobSizes(files, newRev); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, file := range files {
+		c := FileChange{Path: file, Kind: KindText, OldSize: -1, NewSize: -1}
+		if size, ok := oldSizes[file]; ok {
+			c.OldSize = size
+		}
+		if newRev != "" {
+			if size, ok := newSizes[file]; ok {
+				c.NewSize = size
+			}
+		} else if info, err := os.Stat(filepath.Join(current.Root(), file)); err == nil {
+			c.NewSize = info.Size()
+		}
+
+		stat, tracked := numstat[file]
+		switch {
+		case tracked:
+			c.Added, c.Deleted = stat[0], stat[1]
+		case newRev == "":
+			// Untracked files aren't in the numstat, look at their content
+			c.Added, c.Deleted = untrackedStat(filepath.Join(current.Root(), file))
+		}
+
+		switch {
+		case c.Added < 0 || attrs[file]["binary"] == "set" || attrs[file]["diff"] == "unset",
+			attrs[file]["filter"] == "lfs":
+			c.Kind = KindBinary
+			c.Added, c.Deleted = -1, -1
+		case attrs[file]["linguist-generated"] == "set" || attrs[file]["linguist-generated"] == "true":
+			c.Kind = KindGenerated
+		case Limits.MaxLines > 0 && c.Added+c.Deleted > Limits.MaxLines,
+			Limits.MaxBytes > 0 && c.NewSize > Limits.MaxBytes:
+			c.Kind = KindLarge
+		}
+		changes[file] = c
+	}
+	return changes, nil
+}
+
+// FileVersions returns both sides of a file's change, the index and the
+// working tree, or HEAD and the index when staged. A missing side is nil,
+// as are both with a backend other than exec.
+func FileVersions(file string, staged bool) ([]byte, []byte) {
+	if requireExec("reading blobs") != nil {
+		return nil, nil
+	}
+	if staged {
+		return readBlob("HEAD:" + file), readBlob(":" + file)
+	}
+	content, err := os.ReadFile(filepath.Join(current.Root(), file))
+	if err != nil {
+		content = nil
+	}
+	return readBlob(":" + file), content
+}
+
+func readBlob(rev string) []byte {
+	output, err := command("cat-file", "blob", rev).Output()
+	if err != nil {
+		return nil
+	}
+	return output
+}
+
+// numstat returns the added and deleted lines per file, -1 for binary
+// files.
+func numstat(files []string, staged bool) (map[string][2]int, error) {
+	args := []string{"--no-pager", "diff", "--numstat", "-z"}
+	if staged {
+		args = append(args, "--cached")
+	}
+	args = append(append(args, "--"), files...)
+
+	output, err := command(args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("git diff --numstat failed: %w", err)
+	}
+
+	stats := make(map[string][2]int)
+	fields := strings.Split(string(output), "\x00")
+	for i := 0; i < len(fields); i++ {
+		parts := strings.SplitN(fields[i], "\t", 3)
+		if len(parts) < 3 {
+			continue
+		}
+		path := parts[2]
+		if path == "" && i+2 < len(fields) {
+			// Renames are followed by the old and new path
+			path = fields[i+2]
+			i += 2
+		}
+		added, err := strconv.Atoi(parts[0])
+		if err != nil {
+			added = -1
+		}
+		deleted, err := strconv.Atoi(parts[1])
+		if err != nil {
+			deleted = -1
+		}
+		stats[path] = [2]int{added, deleted}
+	}
+	return stats, nil
+}
+
+// checkAttrs returns the attributes relevant for classification per file.
+func checkAttrs(files []string) (map[string]map[string]string, error) {
+	args := append([]string{"check-attr", "-z", "binary", "diff", "filter", "linguist-generated", "--"}, files...)
+	output, err := command(args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("git check-attr failed: %w", err)
+	}
+
+	attrs := make(map[string]map[string]string)
+	fields := strings.Split(string(output), "\x00")
+	for i := 0; i+2 < len(fields); i += 3 {
+		path, attr, value := fields[i], fields[i+1], fields[i+2]
+		if value == "unspecified" {
+			continue
+		}
+		if attrs[path] == nil {
+			attrs[path] = make(map[string]string)
+		}
+		attrs[path][attr] = value
+	}
+	return attrs, nil
+}
+
+// blobSizes looks up the size of each file at rev ("HEAD:" or ":" for the
+// index) with a single "git cat-file --batch-check".
+func blobSizes(files []string, rev string) (map[string]int64, error) {
+	var input bytes.Buffer
+	for _, f := range files {
+		input.WriteString(rev + f + "\n")
+	}
+
+	cmd := command("cat-file", "--batch-check=%(objecttype) %(objectsize)")
+	cmd.Stdin = &input
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git cat-file failed: %w", err)
+	}
+
+	sizes := make(map[string]int64, len(files))
+	lines := strings.Split(strings.TrimSuffix(string(output), "\n"), "\n")
+	for i, line := range lines {
+		if i >= len(files) {
+			break
+		}
+		kind, size, ok := strings.Cut(line, " ")
+		if !ok || kind != "blob" {
+			// Missing objects are reported as "<rev> missing"
+			continue
+		}
+		if n, err := strconv.ParseInt(size, 10, 64); err == nil {
+			sizes[files[i]] = n
+		}
+	}
+	return sizes, nil
+}
+
+// untrackedStat counts the lines of a new file, or reports it as binary.
+func untrackedStat(path string) (int, int) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, 0
+	}
+	defer f.Close()
+
+	head := make([]byte, 8000)
+	n, _ := io.ReadFull(f, head)
+	if isBinary(head[:n]) {
+		return -1, -1
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return 0, 0
+	}
+
+	lines := 0
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines++
+	}
+	return lines, 0
+}
+
+// sizeDelta renders a size change such as "12.0 KB -> 15.3 KB".
+func sizeDelta(oldSize, newSize int64) string {
+	switch {
+	case oldSize < 0 && newSize < 0:
+		return "size unknown"
+	case oldSize < 0:
+		return "added, " + formatSize(newSize)
+	case newSize < 0:
+		return "deleted, was " + formatSize(oldSize)
+	default:
+		return formatSize(oldSize) + " -> " + formatSize(newSize)
+	}
+}
+
+func formatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,80 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestClassifyChanges(t *testing.T) {
+	dir, repo := newExecRepository(t)
+	useRepository(t, repo)
+
+	write := func(name string, content []byte) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), content, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, output)
+		}
+	}
+
+	write(".gitattributes", []byte("*.dat filter=lfs diff=lfs merge=lfs -text\n"))
+	write("old name.txt", []byte(strings.Repeat("line\n", 20)))
+	if err := repo.Commit(CommitOptions{Message: "Add files", StageAll: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	run("mv", "old name.txt", "new name.txt")
+	write("logo.png", []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"))
+	write("model.dat", []byte("version https://git-lfs.github.com/spec/v1\noid sha256:abc\nsize 12345\n"))
+	run("add", "logo.png", "model.dat")
+
+	stats, err := numstat([]string{"old name.txt", "new name.txt", "logo.png"}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := stats["old name.txt"]; ok {
+		t.Errorf("numstat() = %v, want the rename under its new path only", stats)
+	}
+	if stat, ok := stats["new name.txt"]; !ok || stat != [2]int{0, 0} {
+		t.Errorf("numstat() of the rename = %v, want 0 and 0 lines for new name.txt", stats)
+	}
+	if stat := stats["logo.png"]; stat != [2]int{-1, -1} {
+		t.Errorf("numstat() of logo.png = %v, want -1 for a binary file", stat)
+	}
+
+	staged, err := ClassifyChanges([]string{"new name.txt", "logo.png", "model.dat"}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for file, want := range map[string]ChangeKind{"new name.txt": KindText, "logo.png": KindBinary, "model.dat": KindBinary} {
+		if got := staged[file].Kind; got != want {
+			t.Errorf("ClassifyChanges() of %s = %s, want %s", file, got, want)
+		}
+	}
+	if c := staged["logo.png"]; c.OldSize != -1 || c.NewSize != 16 {
+		t.Errorf("logo.png sizes = %d and %d, want -1 and 16", c.OldSize, c.NewSize)
+	}
+
+	write("notes.txt", []byte("one\ntwo\nthree\n"))
+	write("blob.bin", []byte("a\x00b"))
+	unstaged, err := ClassifyChanges([]string{"notes.txt", "blob.bin"}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := unstaged["notes.txt"]; c.Kind != KindText || c.Added != 3 || c.Deleted != 0 || c.NewSize != 14 {
+		t.Errorf("ClassifyChanges() of untracked notes.txt = %+v, want 3 added lines", c)
+	}
+	if c := unstaged["blob.bin"]; c.Kind != KindBinary {
+		t.Errorf("ClassifyChanges() of untracked blob.bin = %+v, want binary", c)
+	}
+}
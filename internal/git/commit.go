@@ -0,0 +1,89 @@
+package git
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// CommitError is returned when git commit fails. It keeps git's output and
+// what most likely went wrong, so the failure can be explained to the user.
+type CommitError struct {
+	Err    error
+	Output string
+	// Hook is the name of the hook that rejected the commit, if any.
+	Hook string
+	// Signing is set when git failed to sign the commit.
+	Signing bool
+}
+
+func (e *CommitError) Error() string {
+	return fmt.Sprintf("git commit failed: %v\nOutput: %s", e.Err, e.Output)
+}
+
+func (e *CommitError) Unwrap() error {
+	return e.Err
+}
+
+func newCommitError(err error, output string, events []byte) *CommitError {
+	e := &CommitError{Err: err, Output: strings.TrimSpace(output)}
+
+	lower := strings.ToLower(output)
+	if strings.Contains(lower, "failed to sign") || strings.Contains(lower, "gpg failed") {
+		e.Signing = true
+		return e
+	}
+	e.Hook = failedHook(events)
+	return e
+}
+
+// traceEvent is the part of a git trace2 event that tells which hooks ran
+// and how they exited.
+type traceEvent struct {
+	Event      string `json:"event"`
+	SID        string `json:"sid"`
+	ChildID    int    `json:"child_id"`
+	ChildClass string `json:"child_class"`
+	HookName   string `json:"hook_name"`
+	Code       int    `json:"code"`
+}
+
+// failedHook returns the hook that exited with an error according to the
+// GIT_TRACE2_EVENT output of a commit, or an empty string. Git doesn't say
+// so in its own output, and older versions don't trace hooks at all.
+func failedHook(events []byte) string {
+	type child struct {
+		sid string
+		id  int
+	}
+	hooks := make(map[child]string)
+	for _, line := range bytes.Split(events, []byte("\n")) {
+		var ev traceEvent
+		if json.Unmarshal(line, &ev) != nil {
+			continue
+		}
+		c := child{sid: ev.SID, id: ev.ChildID}
+		switch {
+		case ev.Event == "child_start" && ev.ChildClass == "hook":
+			hooks[c] = ev.HookName
+		case ev.Event == "child_exit" && ev.Code != 0 && hooks[c] != "":
+			return hooks[c]
+		}
+	}
+	return ""
+}
+
+// IsSigningEnabled reports whether a commit made with the given extra
+// arguments will be signed, either explicitly or via commit.gpgsign.
+func IsSigningEnabled(args []string) bool {
+	for i := len(args) - 1; i >= 0; i-- {
+		switch {
+		case args[i] == "--no-gpg-sign":
+			return false
+		case strings.HasPrefix(args[i], "-S") || strings.HasPrefix(args[i], "--gpg-sign"):
+			return true
+		}
+	}
+	return GetConfig("commit.gpgsign") == "true"
+}
@@ -0,0 +1,95 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestFailedHook(t *testing.T) {
+	tests := []struct {
+		name   string
+		events string
+		want   string
+	}{
+		{
+			name: "commit-msg rejects",
+			events: `{"event":"child_start","sid":"a","child_id":0,"child_class":"hook","hook_name":"pre-commit"}
+{"event":"child_exit","sid":"a","child_id":0,"code":0}
+{"event":"child_start","sid":"a","child_id":1,"child_class":"hook","hook_name":"commit-msg"}
+{"event":"child_exit","sid":"a","child_id":1,"code":1}`,
+			want: "commit-msg",
+		},
+		{
+			name: "hook passes",
+			events: `{"event":"child_start","sid":"a","child_id":0,"child_class":"hook","hook_name":"pre-commit"}
+{"event":"child_exit","sid":"a","child_id":0,"code":0}`,
+		},
+		{
+			name: "other child fails",
+			events: `{"event":"child_start","sid":"a","child_id":0,"child_class":"hook","hook_name":"pre-commit"}
+{"event":"child_exit","sid":"b","child_id":0,"code":1}`,
+		},
+		{name: "no trace"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := failedHook([]byte(tt.events)); got != tt.want {
+				t.Errorf("failedHook() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// newExecRepository creates a repository in a temporary directory, with
+// an isolated config, for the exec backend.
+func newExecRepository(t *testing.T) (string, Repository) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_AUTHOR_NAME", "Test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+	if output, err := exec.Command("git", "init", "-q", dir).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v\n%s", err, output)
+	}
+	repo, err := Open(BackendExec, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir, repo
+}
+
+func TestExecCommitErrorHook(t *testing.T) {
+	dir, repo := newExecRepository(t)
+
+	writeHook := func(name, script string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, ".git", "hooks", name), []byte("#!/bin/sh\n"+script+"\n"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeHook("pre-commit", "exit 0")
+	writeHook("commit-msg", "echo 'missing ticket' >&2; exit 1")
+
+	var commitErr *CommitError
+	err := repo.Commit(CommitOptions{Message: "Add nothing"})
+	if !errors.As(err, &commitErr) || commitErr.Hook != "" {
+		t.Errorf("Commit() without changes = %v, want a failure not blamed on a hook", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err = repo.Commit(CommitOptions{Message: "Add a", StageAll: true})
+	if !errors.As(err, &commitErr) || commitErr.Hook != "commit-msg" {
+		t.Errorf("Commit() = %v, want a failure blamed on commit-msg", err)
+	}
+}
@@ -0,0 +1,112 @@
+package git
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Whitespace modes for DiffOptions, mapping to git diff's ignore flags.
+const (
+	WhitespaceAll    = "all"    // -w
+	WhitespaceChange = "change" // -b
+	WhitespaceEOL    = "eol"    // --ignore-space-at-eol
+	WhitespaceBlank  = "blank"  // --ignore-blank-lines
+)
+
+// DiffOptions control how diffs sent to the model are produced.
+type DiffOptions struct {
+	// Context is the number of context lines (-U), negative for git's
+	// default of 3.
+	Context int
+	// FunctionContext shows the whole function around each change.
+	FunctionContext bool
+	// Algorithm is passed to --diff-algorithm, e.g. histogram or patience.
+	Algorithm string
+	// Whitespace ignores whitespace changes, see the Whitespace constants.
+	Whitespace string
+}
+
+// DefaultDiffOptions is plain "git diff" output.
+var DefaultDiffOptions = DiffOptions{Context: -1}
+
+// RichDiffOptions give the model more context around small changes.
+var RichDiffOptions = DiffOptions{Context: -1, FunctionContext: true, Algorithm: "histogram"}
+
+// DiffOpts are the options used when collecting diffs.
+var DiffOpts = DefaultDiffOptions
+
+// AutoDiffBudget is the size in bytes below which diffs are collected again
+// with RichDiffOptions, as long as the result still fits. Zero disables the
+// automatic choice.
+var AutoDiffBudget = 0
+
+func (o DiffOptions) args() []string {
+	var args []string
+	if o.Context >= 0 {
+		args = append(args, "-U"+strconv.Itoa(o.Context))
+	}
+	if o.FunctionContext {
+		args = append(args, "--function-context")
+	}
+	if o.Algorithm != "" {
+		args = append(args, "--diff-algorithm="+o.Algorithm)
+	}
+	switch o.Whitespace {
+	case WhitespaceAll:
+		args = append(args, "--ignore-all-space")
+	case WhitespaceChange:
+		args = append(args, "--ignore-space-change")
+	case WhitespaceEOL:
+		args = append(args, "--ignore-space-at-eol")
+	case WhitespaceBlank:
+		args = append(args, "--ignore-blank-lines")
+	}
+	return args
+}
+
+// Validate checks the algorithm and whitespace mode.
+func (o DiffOptions) Validate() error {
+	switch o.Algorithm {
+	case "", "myers", "default", "minimal", "patience", "histogram":
+	default:
+		return fmt.Errorf("unknown diff algorithm %q, expected myers, minimal, patience or histogram", o.Algorithm)
+	}
+	switch o.Whitespace {
+	case "", WhitespaceAll, WhitespaceChange, WhitespaceEOL, WhitespaceBlank:
+	default:
+		return fmt.Errorf("unknown whitespace mode %q, expected %s, %s, %s or %s", o.Whitespace, WhitespaceAll, WhitespaceChange, WhitespaceEOL, WhitespaceBlank)
+	}
+	return nil
+}
+
+// collectDiffs runs collect with DiffOpts and, when the result is well
+// within AutoDiffBudget, again with richer options that are kept if they
+// still fit.
+func collectDiffs(collect func(DiffOptions) string) string {
+	diffs := collect(DiffOpts)
+	if AutoDiffBudget <= 0 || len(diffs) == 0 || len(diffs) >= AutoDiffBudget/2 {
+		return diffs
+	}
+	if _, ok := current.(*goGitRepository); ok {
+		// go-git has no function context, the result would be the same
+		return diffs
+	}
+
+	rich := RichDiffOptions
+	rich.Whitespace = DiffOpts.Whitespace
+	if DiffOpts.Algorithm != "" {
+		rich.Algorithm = DiffOpts.Algorithm
+	}
+	if DiffOpts.Context > rich.Context {
+		rich.Context = DiffOpts.Context
+	}
+	richDiffs := collect(rich)
+	if len(richDiffs) > AutoDiffBudget {
+		log.Debug().Msgf("Diffs with function context are %d bytes, over the %d byte budget", len(richDiffs), AutoDiffBudget)
+		return diffs
+	}
+	log.Debug().Msgf("Using function context diffs, %d bytes instead of %d", len(richDiffs), len(diffs))
+	return richDiffs
+}
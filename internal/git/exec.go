@@ -0,0 +1,207 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// execRepository runs every operation through the git binary.
+type execRepository struct {
+	root string
+}
+
+func (r *execRepository) Root() string {
+	return r.root
+}
+
+func (r *execRepository) command(args ...string) *exec.Cmd {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.root
+	return cmd
+}
+
+func (r *execRepository) Status() (string, error) {
+	cmd := r.command("status", "--porcelain")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	if len(output) == 0 {
+		return "", ErrNothingToCommit
+	}
+	return string(output), nil
+}
+
+func (r *execRepository) Diff(file string, opts DiffOptions) (string, error) {
+	args := append(append([]string{"--no-pager", "diff"}, diffPrefixes...), opts.args()...)
+	args = append(args, "--", file)
+	cmd := r.command(args...)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
+
+// diffBatchSize bounds the number of paths per "git diff" so huge
+// changesets stay below the argument length limit.
+const diffBatchSize = 500
+
+// Diffs runs a single "git diff" per batch of files and splits it per file,
+// which is much faster than one process per file on large changesets.
+func (r *execRepository) Diffs(files []string, opts DiffOptions) (map[string]string, error) {
+	diffs := make(map[string]string, len(files))
+	for len(files) > 0 {
+		batch := files[:min(len(files), diffBatchSize)]
+		files = files[len(batch):]
+
+		// Unquoted paths in the headers map back to the requested files
+		args := append(append([]string{"-c", "core.quotePath=false", "--no-pager", "diff"}, diffPrefixes...), opts.args()...)
+		args = append(append(args, "--"), batch...)
+		output, err := r.command(args...).Output()
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range ParseDiff(string(output)) {
+			diffs[f.Path] += f.Patch(f.Hunks)
+		}
+	}
+	return diffs, nil
+}
+
+func (r *execRepository) Log(revRange string) ([]LogEntry, error) {
+	format := "--format=%H" + logFieldSep + "%s" + logFieldSep + "%b" + logRecordSep
+	cmd := r.command("--no-pager", "log", format, revRange)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git log failed: %w", err)
+	}
+
+	var entries []LogEntry
+	for _, record := range strings.Split(string(output), logRecordSep) {
+		record = strings.TrimSpace(record)
+		if record == "" {
+			continue
+		}
+		fields := strings.SplitN(record, logFieldSep, 3)
+		if len(fields) < 3 {
+			return nil, fmt.Errorf("unexpected git log output format")
+		}
+		entries = append(entries, LogEntry{
+			Hash:    fields[0],
+			Subject: fields[1],
+			Body:    strings.TrimSpace(fields[2]),
+		})
+	}
+	return entries, nil
+}
+
+func (r *execRepository) Stage(files ...string) error {
+	args := []string{"add", "-A"}
+	if len(files) > 0 {
+		args = append(append(args, "--"), files...)
+	}
+
+	cmd := r.command(args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("git add failed: %v\nOutput: %s", err, string(output))
+	}
+	return nil
+}
+
+// Commit passes the message through a file rather than -m arguments so
+// multiple paragraphs and exact whitespace survive.
+func (r *execRepository) Commit(opts CommitOptions) error {
+	switch {
+	case len(opts.Files) > 0:
+		if err := r.Stage(opts.Files...); err != nil {
+			return err
+		}
+	case opts.StageAll:
+		if err := r.Stage(); err != nil {
+			return err
+		}
+	}
+
+	msgFile, err := os.CreateTemp("", "commi-msg-*.txt")
+	if err != nil {
+		return fmt.Errorf("failed to create commit message file: %w", err)
+	}
+	defer os.Remove(msgFile.Name())
+
+	if _, err := msgFile.WriteString(opts.Message); err != nil {
+		msgFile.Close()
+		return fmt.Errorf("failed to write commit message file: %w", err)
+	}
+	if err := msgFile.Close(); err != nil {
+		return fmt.Errorf("failed to write commit message file: %w", err)
+	}
+
+	cleanup := opts.Cleanup
+	if cleanup == "" {
+		cleanup = CleanupWhitespace
+	}
+
+	args := []string{"commit", "-F", msgFile.Name(), "--cleanup=" + cleanup}
+	args = append(args, opts.Args...)
+	if len(opts.Files) > 0 {
+		args = append(append(args, "--"), opts.Files...)
+	}
+
+	if IsSigningEnabled(opts.Args) {
+		log.Debug().Msg("Commit will be signed")
+	}
+
+	// Hooks are traced to tell which one rejected the commit
+	traceFile, err := os.CreateTemp("", "commi-trace-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to create trace file: %w", err)
+	}
+	traceFile.Close()
+	defer os.Remove(traceFile.Name())
+
+	cmd := r.command(args...)
+	cmd.Env = append(os.Environ(), "GIT_TRACE2_EVENT="+traceFile.Name())
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		events, _ := os.ReadFile(traceFile.Name())
+		return newCommitError(err, string(output), events)
+	}
+	return nil
+}
+
+func (r *execRepository) CurrentBranch() (string, error) {
+	output, err := r.command("rev-parse", "--abbrev-ref", "HEAD").Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+func (r *execRepository) RecentSubjects(n int) ([]string, error) {
+	output, err := r.command("--no-pager", "log", "-n", strconv.Itoa(n), "--format=%s").Output()
+	if err != nil {
+		return nil, err
+	}
+	var subjects []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			subjects = append(subjects, line)
+		}
+	}
+	return subjects, nil
+}
+
+func (r *execRepository) Config(key string) string {
+	output, err := r.command("config", "--get", key).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
@@ -1,50 +1,115 @@
 package git
 
 import (
+	"errors"
 	"fmt"
-	"os/exec"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/rs/zerolog/log"
 )
 
 func GetGitInfo() (string, string, error) {
+	return GetScopedGitInfo("")
+}
+
+// GetScopedGitInfo is GetGitInfo limited to the changes inside dir, a path
+// relative to the repository root. An empty dir means the whole repository.
+func GetScopedGitInfo(dir string) (string, string, error) {
 	status, err := GetGitStatus()
 	if err != nil {
-		if err.Error() == "nothing to commit" {
-			return "", "", fmt.Errorf("nothing to commit")
+		if errors.Is(err, ErrNothingToCommit) {
+			return "", "", ErrNothingToCommit
 		}
 		return "", "", fmt.Errorf("failed to get git status: %w", err)
 	}
+	if status = ScopeStatus(status, dir); status == "" {
+		return "", "", ErrNothingToCommit
+	}
 
 	files, err := GetChangedFiles(status)
 	if err != nil {
 		return "", "", fmt.Errorf("failed to get changed files: %w", err)
 	}
 
-	diffs := ""
+	return status, GetGitDiffs(files), nil
+}
+
+// GetGitDiffs concatenates the diffs of the given files, skipping the ones
+// git fails to diff. Files matched by the ignore rules only get a stat line,
+// binary, generated and oversized files a summary.
+func GetGitDiffs(files []string) string {
+	start := time.Now()
+	ignore := loadIgnore()
+	changes, err := ClassifyChanges(files, false)
+	if err != nil {
+		log.Debug().Err(err).Msg("Failed to classify changes, diffing every file")
+	}
+	classified := time.Since(start)
+
+	var toDiff []string
 	for _, file := range files {
-		diff, err := GetGitDiff(file)
-		if err != nil {
-			log.Warn().Err(err).Str("file", file).Msg("Failed to get diff for file")
-			continue
+		if c, ok := changes[file]; !ok || c.Kind == KindText {
+			toDiff = append(toDiff, file)
 		}
-		diffs += fmt.Sprintf("Diff for %s:\n%s\n\n", file, diff)
 	}
 
-	return status, diffs, nil
+	// Submodule logs don't depend on the diff options, describe them once
+	// rather than on every collection
+	submodules := describeSubmodules(files)
+
+	diffs := collectDiffs(func(opts DiffOptions) string {
+		fileDiffs, err := current.Diffs(toDiff, opts)
+		if err != nil {
+			log.Debug().Err(err).Msg("Failed to diff all files at once, diffing them one by one")
+		}
+
+		var b strings.Builder
+		for _, file := range files {
+			var diff string
+			switch c, ok := changes[file]; {
+			case ok && c.Kind != KindText:
+				diff = c.Summary()
+			case fileDiffs != nil:
+				diff = fileDiffs[file]
+			default:
+				diff, err = current.Diff(file, opts)
+				if err != nil {
+					log.Warn().Err(err).Str("file", file).Msg("Failed to get diff for file")
+					continue
+				}
+			}
+
+			if diff != "" && isIgnored(ignore, file) {
+				diff = summarizeDiff(file, diff)
+			} else if summary, ok := submodules[file]; ok {
+				diff += summary
+			}
+			fmt.Fprintf(&b, "Diff for %s:\n%s\n\n", file, diff)
+		}
+		return b.String()
+	})
+
+	log.Debug().
+		Int("files", len(files)).
+		Int("bytes", len(diffs)).
+		Dur("classify", classified).
+		Dur("total", time.Since(start)).
+		Msg("Collected diffs")
+	return diffs
 }
 
-func GetGitStatus() (string, error) {
-	cmd := exec.Command("git", "status", "--porcelain")
-	output, err := cmd.Output()
-	if err != nil {
-		return "", err
-	}
-	if len(output) == 0 {
-		return "", fmt.Errorf("nothing to commit")
+// GetRepoRoot returns the top-level directory of the current repository.
+func GetRepoRoot() (string, error) {
+	if root := current.Root(); root != "" {
+		return root, nil
 	}
-	return string(output), nil
+	return FindRoot(".")
+}
+
+func GetGitStatus() (string, error) {
+	return current.Status()
 }
 
 func GetChangedFiles(status string) ([]string, error) {
@@ -63,29 +128,84 @@ func GetChangedFiles(status string) ([]string, error) {
 	return files, nil
 }
 
-func GetGitDiff(file string) (string, error) {
-	cmd := exec.Command("git", "--no-pager", "diff", file)
-	output, err := cmd.Output()
-	if err != nil {
-		return "", err
+// FilterStatus keeps only the porcelain status lines that refer to one of
+// the given files.
+func FilterStatus(status string, files []string) string {
+	wanted := make(map[string]bool, len(files))
+	for _, f := range files {
+		wanted[f] = true
+	}
+
+	var b strings.Builder
+	for _, line := range strings.Split(status, "\n") {
+		parts := strings.Fields(line)
+		if len(parts) < 2 || !wanted[parts[1]] {
+			continue
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
 	}
-	return string(output), nil
+	return b.String()
 }
 
-func ExecuteGitAdd() error {
-	cmd := exec.Command("git", "add", ".")
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("git add failed: %v\nOutput: %s", err, string(output))
+// ScopeStatus keeps only the porcelain status lines for paths inside dir,
+// given relative to the repository root.
+func ScopeStatus(status, dir string) string {
+	dir = strings.Trim(filepath.ToSlash(filepath.Clean(dir)), "/")
+	if dir == "" || dir == "." {
+		return status
+	}
+
+	var b strings.Builder
+	for _, line := range strings.Split(status, "\n") {
+		parts := strings.Fields(line)
+		if len(parts) < 2 {
+			continue
+		}
+		if path := parts[1]; path == dir || strings.HasPrefix(path, dir+"/") {
+			b.WriteString(line)
+			b.WriteString("\n")
+		}
 	}
-	return nil
+	return b.String()
 }
 
-func ExecuteGitCommit(title, message string) error {
-	cmd := exec.Command("git", "commit", "-m", title, "-m", message)
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("git commit failed: %v\nOutput: %s", err, string(output))
+func GetGitDiff(file string) (string, error) {
+	return current.Diff(file, DiffOpts)
+}
+
+// Cleanup modes for the commit message, see "git commit --cleanup".
+const (
+	CleanupWhitespace = "whitespace"
+	CleanupVerbatim   = "verbatim"
+	CleanupStrip      = "strip"
+	CleanupScissors   = "scissors"
+	CleanupDefault    = "default"
+)
+
+type CommitOptions struct {
+	// Message is the complete commit message, title included.
+	Message string
+	// StageAll stages every change in the working tree before committing.
+	StageAll bool
+	// Files limits the commit to these paths, staging them first.
+	Files []string
+	// Cleanup controls how git cleans up the message, defaults to whitespace
+	// so lines starting with '#' are kept.
+	Cleanup string
+	// Args are passed through to git commit.
+	Args []string
+}
+
+// FormatMessage joins a commit title and body into a full message.
+func FormatMessage(title, body string) string {
+	if strings.TrimSpace(body) == "" {
+		return title + "\n"
 	}
-	return nil
+	return title + "\n\n" + strings.TrimRight(body, "\n") + "\n"
+}
+
+// ExecuteGitCommit stages the requested changes and commits them.
+func ExecuteGitCommit(opts CommitOptions) error {
+	return current.Commit(opts)
 }
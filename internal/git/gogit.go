@@ -0,0 +1,515 @@
+package git
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	gogit "github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	fdiff "github.com/go-git/go-git/v5/plumbing/format/diff"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/utils/diff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// goGitRepository implements Repository in pure Go, so no git binary is
+// needed. Hooks, signing and passthrough git commit options are not
+// supported.
+type goGitRepository struct {
+	repo *gogit.Repository
+}
+
+// OpenGoGit opens the repository containing path with go-git.
+func OpenGoGit(path string) (Repository, error) {
+	repo, err := gogit.PlainOpenWithOptions(path, &gogit.PlainOpenOptions{
+		DetectDotGit: true,
+		// Linked worktrees keep refs and objects in the main repository
+		EnableDotGitCommonDir: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository: %w", err)
+	}
+	return NewGoGitRepository(repo), nil
+}
+
+// NewGoGitRepository wraps an already opened go-git repository, e.g. one
+// kept in memory.
+func NewGoGitRepository(repo *gogit.Repository) Repository {
+	return &goGitRepository{repo: repo}
+}
+
+func (r *goGitRepository) Root() string {
+	wt, err := r.repo.Worktree()
+	if err != nil {
+		return ""
+	}
+	return wt.Filesystem.Root()
+}
+
+func (r *goGitRepository) Status() (string, error) {
+	wt, err := r.repo.Worktree()
+	if err != nil {
+		return "", err
+	}
+	status, err := wt.Status()
+	if err != nil {
+		return "", err
+	}
+
+	var lines []string
+	for path, s := range status {
+		if s.Staging == gogit.Unmodified && s.Worktree == gogit.Unmodified {
+			continue
+		}
+		if s.Staging == gogit.Renamed {
+			path = fmt.Sprintf("%s -> %s", s.Extra, path)
+		}
+		lines = append(lines, fmt.Sprintf("%c%c %s", s.Staging, s.Worktree, path))
+	}
+	if len(lines) == 0 {
+		return "", ErrNothingToCommit
+	}
+
+	// Porcelain output is sorted by path
+	sort.Slice(lines, func(i, j int) bool { return lines[i][3:] < lines[j][3:] })
+	return strings.Join(lines, "\n") + "\n", nil
+}
+
+// Diff compares the index with the working tree, like "git diff <file>".
+// Untracked files have no diff. Only the context lines of the options are
+// supported.
+func (r *goGitRepository) Diff(file string, opts DiffOptions) (string, error) {
+	idx, err := r.repo.Storer.Index()
+	if err != nil {
+		return "", err
+	}
+	entry, err := idx.Entry(file)
+	if errors.Is(err, index.ErrEntryNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if entry.Mode == filemode.Submodule {
+		return r.submoduleDiff(file, entry.Hash)
+	}
+
+	blob, err := r.repo.BlobObject(entry.Hash)
+	if err != nil {
+		return "", err
+	}
+	reader, err := blob.Reader()
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+	oldContent, err := io.ReadAll(reader)
+	if err != nil {
+		return "", err
+	}
+
+	wt, err := r.repo.Worktree()
+	if err != nil {
+		return "", err
+	}
+
+	from := &patchFile{path: file, hash: entry.Hash, mode: entry.Mode}
+	var to *patchFile
+	var newContent []byte
+
+	f, err := wt.Filesystem.Open(file)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		// Deleted in the working tree
+	case err != nil:
+		return "", err
+	default:
+		newContent, err = io.ReadAll(f)
+		f.Close()
+		if err != nil {
+			return "", err
+		}
+		to = &patchFile{
+			path: file,
+			hash: plumbing.ComputeHash(plumbing.BlobObject, newContent),
+			mode: entry.Mode,
+		}
+	}
+
+	if to != nil && to.hash == from.hash {
+		return "", nil
+	}
+
+	fp := &filePatch{from: from, to: to}
+	if isBinary(oldContent) || isBinary(newContent) {
+		fp.binary = true
+	} else {
+		for _, d := range diff.Do(string(oldContent), string(newContent)) {
+			fp.chunks = append(fp.chunks, patchChunk{content: d.Text, op: chunkOperation(d.Type)})
+		}
+	}
+
+	var buf bytes.Buffer
+	contextLines := fdiff.DefaultContextLines
+	if opts.Context >= 0 {
+		contextLines = opts.Context
+	}
+	if err := fdiff.NewUnifiedEncoder(&buf, contextLines).Encode(&patch{files: []fdiff.FilePatch{fp}}); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// submoduleDiff renders a submodule pointer change the way git does,
+// comparing the recorded commit with the submodule's checked out HEAD.
+func (r *goGitRepository) submoduleDiff(file string, recorded plumbing.Hash) (string, error) {
+	sub, err := gogit.PlainOpen(filepath.Join(r.Root(), file))
+	if errors.Is(err, gogit.ErrRepositoryNotExists) {
+		// Not checked out
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	head, err := sub.Head()
+	if err != nil {
+		return "", err
+	}
+
+	newRev := head.Hash().String()
+	if wt, err := sub.Worktree(); err == nil {
+		if status, err := wt.Status(); err == nil && !status.IsClean() {
+			newRev += "-dirty"
+		}
+	}
+	if newRev == recorded.String() {
+		return "", nil
+	}
+
+	return fmt.Sprintf("diff --git a/%[1]s b/%[1]s\nindex %[2]s..%[3]s 160000\n--- a/%[1]s\n+++ b/%[1]s\n@@ -1 +1 @@\n-%[4]s%[5]s\n+%[4]s%[6]s\n",
+		file, shortHash(recorded.String()), shortHash(head.Hash().String()), subprojectPrefix, recorded, newRev), nil
+}
+
+func (r *goGitRepository) Diffs(files []string, opts DiffOptions) (map[string]string, error) {
+	diffs := make(map[string]string, len(files))
+	for _, file := range files {
+		diff, err := r.Diff(file, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to diff %s: %w", file, err)
+		}
+		if diff != "" {
+			diffs[file] = diff
+		}
+	}
+	return diffs, nil
+}
+
+func (r *goGitRepository) Log(revRange string) ([]LogEntry, error) {
+	from, to := "", revRange
+	if i := strings.Index(revRange, ".."); i != -1 {
+		from, to = revRange[:i], revRange[i+2:]
+	}
+	if to == "" {
+		to = "HEAD"
+	}
+
+	// Commits reachable from the start of the range are excluded
+	exclude := make(map[plumbing.Hash]bool)
+	if from != "" {
+		fromHash, err := r.repo.ResolveRevision(plumbing.Revision(from))
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s: %w", from, err)
+		}
+		iter, err := r.repo.Log(&gogit.LogOptions{From: *fromHash})
+		if err != nil {
+			return nil, err
+		}
+		if err := iter.ForEach(func(c *object.Commit) error {
+			exclude[c.Hash] = true
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	toHash, err := r.repo.ResolveRevision(plumbing.Revision(to))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", to, err)
+	}
+	iter, err := r.repo.Log(&gogit.LogOptions{From: *toHash})
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []LogEntry
+	err = iter.ForEach(func(c *object.Commit) error {
+		if exclude[c.Hash] {
+			return nil
+		}
+		subject, body, _ := strings.Cut(strings.TrimSpace(c.Message), "\n")
+		entries = append(entries, LogEntry{
+			Hash:    c.Hash.String(),
+			Subject: strings.TrimSpace(subject),
+			Body:    strings.TrimSpace(body),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (r *goGitRepository) Stage(files ...string) error {
+	wt, err := r.repo.Worktree()
+	if err != nil {
+		return err
+	}
+
+	if len(files) == 0 {
+		return wt.AddWithOptions(&gogit.AddOptions{All: true})
+	}
+	for _, f := range files {
+		if err := wt.AddWithOptions(&gogit.AddOptions{Path: f}); err != nil {
+			return fmt.Errorf("failed to stage %s: %w", f, err)
+		}
+	}
+	return nil
+}
+
+func (r *goGitRepository) Commit(opts CommitOptions) error {
+	if len(opts.Args) > 0 {
+		return fmt.Errorf("git commit options are not supported by the %s backend", BackendGoGit)
+	}
+
+	if r.signsCommits() {
+		return fmt.Errorf("signed commits are not supported by the %s backend, use the %s backend", BackendGoGit, BackendExec)
+	}
+
+	switch {
+	case len(opts.Files) > 0:
+		if err := r.Stage(opts.Files...); err != nil {
+			return err
+		}
+		if err := r.checkOnlyStaged(opts.Files); err != nil {
+			return err
+		}
+	case opts.StageAll:
+		if err := r.Stage(); err != nil {
+			return err
+		}
+	}
+
+	wt, err := r.repo.Worktree()
+	if err != nil {
+		return err
+	}
+	if _, err := wt.Commit(cleanupMessage(opts.Message, opts.Cleanup), &gogit.CommitOptions{}); err != nil {
+		return fmt.Errorf("failed to commit: %w", err)
+	}
+	return nil
+}
+
+// signsCommits reports whether commit.gpgsign is set.
+func (r *goGitRepository) signsCommits() bool {
+	sign, err := strconv.ParseBool(r.Config("commit.gpgsign"))
+	return err == nil && sign
+}
+
+// Config looks the key up in the repository's config, then the global one.
+// They are read separately since the scoped config go-git merges doesn't
+// keep raw sections.
+func (r *goGitRepository) Config(key string) string {
+	section, name := key, ""
+	if i := strings.LastIndex(key, "."); i != -1 {
+		section, name = key[:i], key[i+1:]
+	}
+	subsection := ""
+	if i := strings.Index(section, "."); i != -1 {
+		section, subsection = section[:i], section[i+1:]
+	}
+
+	var configs []*gitconfig.Config
+	if local, err := r.repo.Config(); err == nil {
+		configs = append(configs, local)
+	}
+	if global, err := gitconfig.LoadConfig(gitconfig.GlobalScope); err == nil {
+		configs = append(configs, global)
+	}
+	for _, cfg := range configs {
+		if subsection != "" {
+			if s := cfg.Raw.Section(section); s.HasSubsection(subsection) && s.Subsection(subsection).HasOption(name) {
+				return s.Subsection(subsection).Option(name)
+			}
+		} else if cfg.Raw.Section(section).HasOption(name) {
+			return cfg.Raw.Section(section).Option(name)
+		}
+	}
+	return ""
+}
+
+func (r *goGitRepository) CurrentBranch() (string, error) {
+	head, err := r.repo.Head()
+	if err != nil {
+		return "", err
+	}
+	if !head.Name().IsBranch() {
+		return "HEAD", nil
+	}
+	return head.Name().Short(), nil
+}
+
+func (r *goGitRepository) RecentSubjects(n int) ([]string, error) {
+	head, err := r.repo.Head()
+	if err != nil {
+		return nil, err
+	}
+	iter, err := r.repo.Log(&gogit.LogOptions{From: head.Hash()})
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	var subjects []string
+	for len(subjects) < n {
+		c, err := iter.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		subject, _, _ := strings.Cut(c.Message, "\n")
+		subjects = append(subjects, strings.TrimSpace(subject))
+	}
+	return subjects, nil
+}
+
+// checkOnlyStaged makes sure nothing but the given files is staged, since
+// go-git can't commit a subset of the index.
+func (r *goGitRepository) checkOnlyStaged(files []string) error {
+	wt, err := r.repo.Worktree()
+	if err != nil {
+		return err
+	}
+	status, err := wt.Status()
+	if err != nil {
+		return err
+	}
+
+	wanted := make(map[string]bool, len(files))
+	for _, f := range files {
+		wanted[f] = true
+	}
+	for path, s := range status {
+		if s.Staging != gogit.Unmodified && s.Staging != gogit.Untracked && !wanted[path] {
+			return fmt.Errorf("%s is staged but not part of the commit, the %s backend can't commit a subset of the index", path, BackendGoGit)
+		}
+	}
+	return nil
+}
+
+// cleanupMessage mimics "git commit --cleanup" for the modes that matter
+// when the message isn't edited.
+func cleanupMessage(message, mode string) string {
+	if mode == CleanupVerbatim {
+		return message
+	}
+
+	var lines []string
+	for _, line := range strings.Split(message, "\n") {
+		if mode == CleanupScissors && strings.HasPrefix(line, "# ------------------------ >8 ------------------------") {
+			break
+		}
+		if mode == CleanupStrip && strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimRight(line, " \t\r")
+		// Collapse consecutive blank lines
+		if line == "" && (len(lines) == 0 || lines[len(lines)-1] == "") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n") + "\n"
+}
+
+func isBinary(content []byte) bool {
+	const sniffLen = 8000
+	if len(content) > sniffLen {
+		content = content[:sniffLen]
+	}
+	return bytes.IndexByte(content, 0) != -1
+}
+
+func chunkOperation(t diffmatchpatch.Operation) fdiff.Operation {
+	switch t {
+	case diffmatchpatch.DiffInsert:
+		return fdiff.Add
+	case diffmatchpatch.DiffDelete:
+		return fdiff.Delete
+	default:
+		return fdiff.Equal
+	}
+}
+
+// The types below implement go-git's diff interfaces so the unified
+// encoder can render working tree changes.
+
+type patch struct {
+	files []fdiff.FilePatch
+}
+
+func (p *patch) FilePatches() []fdiff.FilePatch { return p.files }
+func (p *patch) Message() string                { return "" }
+
+type filePatch struct {
+	from, to *patchFile
+	binary   bool
+	chunks   []fdiff.Chunk
+}
+
+func (p *filePatch) IsBinary() bool { return p.binary }
+func (p *filePatch) Chunks() []fdiff.Chunk {
+	return p.chunks
+}
+func (p *filePatch) Files() (fdiff.File, fdiff.File) {
+	// Avoid returning typed nil pointers as non-nil interfaces
+	var from, to fdiff.File
+	if p.from != nil {
+		from = p.from
+	}
+	if p.to != nil {
+		to = p.to
+	}
+	return from, to
+}
+
+type patchFile struct {
+	path string
+	hash plumbing.Hash
+	mode filemode.FileMode
+}
+
+func (f *patchFile) Hash() plumbing.Hash     { return f.hash }
+func (f *patchFile) Mode() filemode.FileMode { return f.mode }
+func (f *patchFile) Path() string            { return f.path }
+
+type patchChunk struct {
+	content string
+	op      fdiff.Operation
+}
+
+func (c patchChunk) Content() string       { return c.content }
+func (c patchChunk) Type() fdiff.Operation { return c.op }
@@ -0,0 +1,183 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-billy/v5/memfs"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+// newMemoryRepository returns an empty repository kept in memory, with an
+// isolated global config.
+func newMemoryRepository(t *testing.T) (*gogit.Repository, Repository) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+
+	repo, err := gogit.Init(memory.NewStorage(), memfs.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := repo.Config()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Memory storage keeps the config as is, without syncing Raw
+	cfg.User.Name = "Test"
+	cfg.User.Email = "test@example.com"
+	cfg.Raw.Section("user").SetOption("name", "Test").SetOption("email", "test@example.com")
+	if err := repo.SetConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+	return repo, NewGoGitRepository(repo)
+}
+
+func writeFile(t *testing.T, repo *gogit.Repository, name, content string) {
+	t.Helper()
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := wt.Filesystem.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGoGitCommitRefusesSigning(t *testing.T) {
+	tests := []struct {
+		name   string
+		global string
+		local  string
+		refuse bool
+	}{
+		{name: "unset"},
+		{name: "global", global: "true", refuse: true},
+		{name: "local", local: "true", refuse: true},
+		{name: "local overrides global", global: "true", local: "false"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, r := newMemoryRepository(t)
+			if tt.global != "" {
+				gitconfig := "[commit]\n\tgpgsign = " + tt.global + "\n"
+				if err := os.WriteFile(filepath.Join(os.Getenv("HOME"), ".gitconfig"), []byte(gitconfig), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.local != "" {
+				cfg, _ := repo.Config()
+				cfg.Raw.Section("commit").SetOption("gpgsign", tt.local)
+				if err := repo.SetConfig(cfg); err != nil {
+					t.Fatal(err)
+				}
+			}
+			writeFile(t, repo, "a.txt", "a\n")
+
+			err := r.Commit(CommitOptions{Message: "Add a", StageAll: true})
+			if tt.refuse {
+				if err == nil || !strings.Contains(err.Error(), "signed commits") {
+					t.Errorf("Commit() = %v, want signing error", err)
+				}
+			} else if err != nil {
+				t.Errorf("Commit() = %v", err)
+			}
+		})
+	}
+}
+
+// useRepository makes r the repository of the package level helpers for
+// the duration of the test.
+func useRepository(t *testing.T, r Repository) {
+	t.Helper()
+	previous := current
+	Use(r)
+	t.Cleanup(func() { Use(previous) })
+}
+
+func TestGoGitRepositoryInMemory(t *testing.T) {
+	repo, r := newMemoryRepository(t)
+	useRepository(t, r)
+
+	if _, err := GetGitStatus(); !errors.Is(err, ErrNothingToCommit) {
+		t.Fatalf("GetGitStatus() on an empty repository = %v, want ErrNothingToCommit", err)
+	}
+
+	writeFile(t, repo, "a.txt", "one\n")
+	status, err := GetGitStatus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "?? a.txt\n"; status != want {
+		t.Errorf("GetGitStatus() = %q, want %q", status, want)
+	}
+	if err := r.Commit(CommitOptions{Message: "Add a", StageAll: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	writeFile(t, repo, "a.txt", "one\ntwo\n")
+	diff, err := r.Diff("a.txt", DiffOptions{Context: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(diff, "+two") {
+		t.Errorf("Diff() = %q, want the added line", diff)
+	}
+	if err := r.Commit(CommitOptions{Message: "Add two\n\nSecond line.", StageAll: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := GetLog("HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Subject != "Add two" || entries[0].Body != "Second line." {
+		t.Errorf("GetLog() = %+v, want both commits, newest first", entries)
+	}
+	if got := GetRecentSubjects(1); len(got) != 1 || got[0] != "Add two" {
+		t.Errorf("GetRecentSubjects(1) = %q, want [Add two]", got)
+	}
+	if branch, err := GetCurrentBranch(); err != nil || branch != "master" {
+		t.Errorf("GetCurrentBranch() = %q, %v, want master", branch, err)
+	}
+	if got := GetConfig("user.email"); got != "test@example.com" {
+		t.Errorf("GetConfig(user.email) = %q, want test@example.com", got)
+	}
+}
+
+func TestExecOnlyHelpersFailWithGoGit(t *testing.T) {
+	_, r := newMemoryRepository(t)
+	useRepository(t, r)
+
+	helpers := map[string]func() error{
+		"GetDefaultBranch": func() error { _, err := GetDefaultBranch(); return err },
+		"GetMergeBase":     func() error { _, err := GetMergeBase("a", "b"); return err },
+		"CreateBranch":     func() error { return CreateBranch("feature") },
+		"GetRangeDiff":     func() error { _, err := GetRangeDiff("a", "b"); return err },
+		"GetRangeStat":     func() error { _, err := GetRangeStat("a", "b"); return err },
+		"GetWorkingDiff":   func() error { _, err := GetWorkingDiff(); return err },
+		"GetStagedDiff":    func() error { _, err := GetStagedDiff(); return err },
+		"GetStagedStatus":  func() error { _, err := GetStagedStatus(); return err },
+		"ApplyCached":      func() error { return ApplyCached("") },
+		"InterpretTrailers": func() error {
+			_, err := InterpretTrailers("Title", []string{"Refs: CR-1"})
+			return err
+		},
+		"ClassifyChanges": func() error { _, err := ClassifyChanges([]string{"a.txt"}, false); return err },
+	}
+	for name, helper := range helpers {
+		if err := helper(); !errors.Is(err, ErrExecOnly) {
+			t.Errorf("%s() = %v, want ErrExecOnly", name, err)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package git
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Hunk is a single "@@ ... @@" section of a file diff.
+type Hunk struct {
+	Header string
+	Lines  []string
+}
+
+// FileDiff is the diff of one file split into its header and hunks.
+type FileDiff struct {
+	Path   string
+	Header []string
+	Hunks  []Hunk
+}
+
+// diffPrefixes pin the "a/" and "b/" prefixes ParseDiff relies on, whatever
+// diff.noprefix or diff.mnemonicPrefix say.
+var diffPrefixes = []string{"--src-prefix=a/", "--dst-prefix=b/"}
+
+// ParseDiff splits the output of "git diff" into per-file diffs and hunks.
+func ParseDiff(diff string) []FileDiff {
+	var files []FileDiff
+	var current *FileDiff
+	var hunk *Hunk
+
+	flushHunk := func() {
+		if current != nil && hunk != nil {
+			current.Hunks = append(current.Hunks, *hunk)
+		}
+		hunk = nil
+	}
+	flushFile := func() {
+		flushHunk()
+		if current != nil {
+			files = append(files, *current)
+		}
+		current = nil
+	}
+
+	for _, line := range strings.Split(strings.TrimSuffix(diff, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			flushFile()
+			current = &FileDiff{Path: diffPath(line), Header: []string{line}}
+		case current == nil:
+			continue
+		case strings.HasPrefix(line, "@@"):
+			flushHunk()
+			hunk = &Hunk{Header: line}
+		case hunk != nil:
+			hunk.Lines = append(hunk.Lines, line)
+		default:
+			current.Header = append(current.Header, line)
+			switch {
+			case strings.HasPrefix(line, "+++ b/"):
+				// Git ends names containing spaces with a tab
+				current.Path = strings.TrimSuffix(strings.TrimPrefix(line, "+++ b/"), "\t")
+			case strings.HasPrefix(line, "rename to "):
+				current.Path = strings.TrimPrefix(line, "rename to ")
+			}
+		}
+	}
+	flushFile()
+
+	return files
+}
+
+// diffPath extracts the new path from a "diff --git a/x b/x" line. Both
+// paths are the same unless the file was renamed, in which case the
+// "rename to" or "+++" header that follows has the new path.
+func diffPath(line string) string {
+	paths := strings.TrimPrefix(line, "diff --git ")
+	if n := len(paths); n%2 == 1 {
+		oldPath, newPath := paths[:n/2], paths[n/2+1:]
+		if paths[n/2] == ' ' && strings.HasPrefix(oldPath, "a/") && strings.HasPrefix(newPath, "b/") && oldPath[2:] == newPath[2:] {
+			return newPath[2:]
+		}
+	}
+	if i := strings.LastIndex(paths, " b/"); i != -1 {
+		return paths[i+len(" b/"):]
+	}
+	return paths
+}
+
+// Patch renders the file header and the given hunks as a patch that can be
+// fed to "git apply".
+func (f FileDiff) Patch(hunks []Hunk) string {
+	var b strings.Builder
+	for _, line := range f.Header {
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	for _, h := range hunks {
+		b.WriteString(h.Header)
+		b.WriteString("\n")
+		for _, line := range h.Lines {
+			b.WriteString(line)
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// GetWorkingDiff returns the unstaged changes of all tracked files, followed
+// by untracked files as new file diffs.
+func GetWorkingDiff() (string, error) {
+	if err := requireExec("git diff"); err != nil {
+		return "", err
+	}
+	cmd := command(append([]string{"--no-pager", "diff"}, diffPrefixes...)...)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+
+	untracked, err := command("ls-files", "--others", "--exclude-standard", "-z").Output()
+	if err != nil {
+		return "", err
+	}
+	diff := string(output)
+	for _, file := range strings.Split(string(untracked), "\x00") {
+		if file == "" {
+			continue
+		}
+		// --no-index exits with 1 when the files differ, which they do
+		fileDiff, err := command(append(append([]string{"--no-pager", "diff", "--no-index"}, diffPrefixes...), "--", "/dev/null", file)...).Output()
+		var exitErr *exec.ExitError
+		if err != nil && !(errors.As(err, &exitErr) && exitErr.ExitCode() == 1) {
+			return "", fmt.Errorf("failed to diff untracked file %s: %w", file, err)
+		}
+		diff += string(fileDiff)
+	}
+	return diff, nil
+}
+
+// GetStagedDiff returns the changes currently staged in the index, using
+// the configured diff options.
+func GetStagedDiff() (string, error) {
+	if err := requireExec("git diff --cached"); err != nil {
+		return "", err
+	}
+	var err error
+	diffs := collectDiffs(func(opts DiffOptions) string {
+		args := append(append([]string{"--no-pager", "diff", "--cached"}, diffPrefixes...), opts.args()...)
+		args = append(args, "--")
+		output, cmdErr := command(args...).Output()
+		if cmdErr != nil && err == nil {
+			err = cmdErr
+		}
+		return string(output)
+	})
+	if err != nil {
+		return "", err
+	}
+	return diffs, nil
+}
+
+// GetStagedStatus returns the name and status of every staged file.
+func GetStagedStatus() (string, error) {
+	if err := requireExec("git diff --cached"); err != nil {
+		return "", err
+	}
+	cmd := command("diff", "--cached", "--name-status")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	if len(output) == 0 {
+		return "", ErrNothingToCommit
+	}
+	return string(output), nil
+}
+
+// ApplyCached stages a patch without touching the working tree.
+func ApplyCached(patch string) error {
+	if err := requireExec("git apply"); err != nil {
+		return err
+	}
+	cmd := command("apply", "--cached", "--recount", "-")
+	cmd.Stdin = bytes.NewBufferString(patch)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("git apply failed: %v\nOutput: %s", err, string(output))
+	}
+	return nil
+}
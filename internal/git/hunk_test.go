@@ -0,0 +1,176 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const sampleDiff = `diff --git a/a.txt b/a.txt
+index 1111111..2222222 100644
+--- a/a.txt
++++ b/a.txt
+@@ -1,3 +1,3 @@
+-one
++ONE
+ two
+ three
+@@ -10,3 +10,3 @@
+ ten
+-eleven
++ELEVEN
+ twelve
+diff --git a/b.txt b/b.txt
+new file mode 100644
+index 0000000..3333333
+--- /dev/null
++++ b/b.txt
+@@ -0,0 +1 @@
++new
+`
+
+func TestParseDiffPatchRoundTrip(t *testing.T) {
+	files := ParseDiff(sampleDiff)
+	if len(files) != 2 || files[0].Path != "a.txt" || files[1].Path != "b.txt" {
+		t.Fatalf("ParseDiff() = %+v, want a.txt and b.txt", files)
+	}
+	if len(files[0].Hunks) != 2 || len(files[1].Hunks) != 1 {
+		t.Fatalf("ParseDiff() hunks = %d and %d, want 2 and 1", len(files[0].Hunks), len(files[1].Hunks))
+	}
+
+	var patch string
+	for _, f := range files {
+		patch += f.Patch(f.Hunks)
+	}
+	if patch != sampleDiff {
+		t.Errorf("Patch() of every hunk =\n%s\nwant the original diff\n%s", patch, sampleDiff)
+	}
+
+	first := files[0].Patch(files[0].Hunks[:1])
+	if !strings.Contains(first, "+ONE") || strings.Contains(first, "+ELEVEN") {
+		t.Errorf("Patch() of the first hunk =\n%s", first)
+	}
+}
+
+func TestParseDiffPaths(t *testing.T) {
+	tests := []struct {
+		name string
+		diff string
+		want string
+	}{
+		{
+			name: "space in path",
+			diff: "diff --git a/a b/c.txt b/a b/c.txt\nindex 1111111..2222222 100644\n--- a/a b/c.txt\t\n+++ b/a b/c.txt\t\n@@ -1 +1 @@\n-x\n+y\n",
+			want: "a b/c.txt",
+		},
+		{
+			name: "space in binary path",
+			diff: "diff --git a/a b/c.png b/a b/c.png\nindex 1111111..2222222 100644\nBinary files a/a b/c.png and b/a b/c.png differ\n",
+			want: "a b/c.png",
+		},
+		{
+			name: "rename",
+			diff: "diff --git a/old name.txt b/new name.txt\nsimilarity index 100%\nrename from old name.txt\nrename to new name.txt\n",
+			want: "new name.txt",
+		},
+		{
+			name: "deleted",
+			diff: "diff --git a/gone.txt b/gone.txt\ndeleted file mode 100644\nindex 1111111..0000000\n--- a/gone.txt\n+++ /dev/null\n@@ -1 +0,0 @@\n-x\n",
+			want: "gone.txt",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := ParseDiff(tt.diff)
+			if len(files) != 1 || files[0].Path != tt.want {
+				t.Errorf("ParseDiff() = %+v, want path %q", files, tt.want)
+			}
+		})
+	}
+}
+
+func TestWorkingDiffIgnoresPrefixConfig(t *testing.T) {
+	dir, repo := newExecRepository(t)
+	useRepository(t, repo)
+
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("one\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Commit(CommitOptions{Message: "Add a", StageAll: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("two\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, setting := range []string{"diff.noprefix", "diff.mnemonicPrefix"} {
+		cmd := exec.Command("git", "config", setting, "true")
+		cmd.Dir = dir
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git config %s: %v\n%s", setting, err, output)
+		}
+		diff, err := GetWorkingDiff()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if files := ParseDiff(diff); len(files) != 1 || files[0].Path != "a.txt" {
+			t.Errorf("with %s, ParseDiff(GetWorkingDiff()) = %+v, want a.txt", setting, files)
+		}
+	}
+}
+
+func TestApplyCachedSelectedHunks(t *testing.T) {
+	dir, repo := newExecRepository(t)
+	useRepository(t, repo)
+
+	var lines []string
+	for i := 1; i <= 20; i++ {
+		lines = append(lines, "line")
+	}
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("a.txt", strings.Join(lines, "\n")+"\n")
+	if err := repo.Commit(CommitOptions{Message: "Add a", StageAll: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	lines[0], lines[19] = "first", "last"
+	write("a.txt", strings.Join(lines, "\n")+"\n")
+	write("new.txt", "new\n")
+
+	diff, err := GetWorkingDiff()
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := ParseDiff(diff)
+	if len(files) != 2 || files[1].Path != "new.txt" {
+		t.Fatalf("GetWorkingDiff() files = %+v, want a.txt and the untracked new.txt", files)
+	}
+	if len(files[0].Hunks) != 2 {
+		t.Fatalf("a.txt has %d hunks, want 2", len(files[0].Hunks))
+	}
+
+	patch := files[0].Patch(files[0].Hunks[:1]) + files[1].Patch(files[1].Hunks)
+	if err := ApplyCached(patch); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command("git", "diff", "--cached")
+	cmd.Dir = dir
+	staged, err := cmd.Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(staged), "+first") || !strings.Contains(string(staged), "+new") {
+		t.Errorf("staged diff misses the selected hunks:\n%s", staged)
+	}
+	if strings.Contains(string(staged), "+last") {
+		t.Errorf("staged diff has the unselected hunk:\n%s", staged)
+	}
+}
@@ -0,0 +1,129 @@
+package git
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+	"github.com/rs/zerolog/log"
+)
+
+// IgnoreFile lists, in gitignore syntax, the files whose diff content is
+// not sent to the model. Patterns are read from the repository root and
+// may re-include defaults with "!".
+const IgnoreFile = ".commiignore"
+
+// DefaultIgnorePatterns cover files that are large, generated or vendored
+// and rarely explain a change.
+var DefaultIgnorePatterns = []string{
+	// Lockfiles
+	"package-lock.json",
+	"yarn.lock",
+	"pnpm-lock.yaml",
+	"go.sum",
+	"Cargo.lock",
+	"Gemfile.lock",
+	"composer.lock",
+	"poetry.lock",
+	"Pipfile.lock",
+	// Vendored code
+	"vendor/",
+	"node_modules/",
+	// Snapshots
+	"__snapshots__/",
+	"*.snap",
+	// Generated code
+	"*.pb.go",
+	"*_pb2.py",
+	"*.pb.cc",
+	"*.pb.h",
+	"*.min.js",
+	"*.min.css",
+}
+
+// loadIgnore builds the matcher from the defaults and the repository's
+// ignore file.
+func loadIgnore() gitignore.Matcher {
+	var patterns []gitignore.Pattern
+	for _, p := range DefaultIgnorePatterns {
+		patterns = append(patterns, gitignore.ParsePattern(p, nil))
+	}
+
+	root, err := GetRepoRoot()
+	if err != nil {
+		return gitignore.NewMatcher(patterns)
+	}
+	f, err := os.Open(filepath.Join(root, IgnoreFile))
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			log.Warn().Err(err).Msgf("Failed to read %s", IgnoreFile)
+		}
+		return gitignore.NewMatcher(patterns)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, gitignore.ParsePattern(line, nil))
+	}
+	if err := scanner.Err(); err != nil {
+		log.Warn().Err(err).Msgf("Failed to read %s", IgnoreFile)
+	}
+	return gitignore.NewMatcher(patterns)
+}
+
+func isIgnored(m gitignore.Matcher, file string) bool {
+	return m.Match(strings.Split(filepath.ToSlash(file), "/"), false)
+}
+
+// summarizeDiff replaces the content of a diff with a one-line stat.
+func summarizeDiff(file, diff string) string {
+	if strings.Contains(diff, "\nBinary files ") || strings.HasPrefix(diff, "Binary files ") {
+		return fmt.Sprintf("%s | binary file changed (content omitted)\n", file)
+	}
+
+	var added, deleted int
+	inHunk := false
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			inHunk = true
+		case strings.HasPrefix(line, "diff --git "):
+			inHunk = false
+		case !inHunk:
+			// The "---" and "+++" file names, not removed or added lines
+		case strings.HasPrefix(line, "+"):
+			added++
+		case strings.HasPrefix(line, "-"):
+			deleted++
+		}
+	}
+	return fmt.Sprintf("%s | %d insertions(+), %d deletions(-) (content omitted)\n", file, added, deleted)
+}
+
+// FilterDiff replaces the content of ignored files in a multi-file diff,
+// e.g. "git diff --cached", with a one-line stat, and the content of
+// non-text changes with their summary.
+func FilterDiff(diff string, changes map[string]FileChange) string {
+	m := loadIgnore()
+
+	var b strings.Builder
+	for _, f := range ParseDiff(diff) {
+		patch := f.Patch(f.Hunks)
+		if c, ok := changes[f.Path]; ok && c.Kind != KindText {
+			patch = c.Summary()
+		} else if isIgnored(m, f.Path) {
+			patch = summarizeDiff(f.Path, patch)
+		}
+		b.WriteString(patch)
+	}
+	return b.String()
+}
@@ -0,0 +1,70 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSummarizeDiff(t *testing.T) {
+	diff := `diff --git a/schema.sql b/schema.sql
+index 1111111..2222222 100644
+--- a/schema.sql
++++ b/schema.sql
+@@ -1,4 +1,3 @@
+--- Users
+-CREATE TABLE users (id INT);
++CREATE TABLE accounts (id INT);
+ ---
+@@ -10,2 +9,3 @@
+ SELECT 1;
++++counter;
++---
+`
+	want := "schema.sql | 3 insertions(+), 2 deletions(-) (content omitted)\n"
+	if got := summarizeDiff("schema.sql", diff); got != want {
+		t.Errorf("summarizeDiff() = %q, want %q", got, want)
+	}
+
+	binary := "diff --git a/logo.png b/logo.png\nindex 1111111..2222222 100644\nBinary files a/logo.png and b/logo.png differ\n"
+	want = "logo.png | binary file changed (content omitted)\n"
+	if got := summarizeDiff("logo.png", binary); got != want {
+		t.Errorf("summarizeDiff() of a binary file = %q, want %q", got, want)
+	}
+}
+
+func fileDiff(path string, lines ...string) string {
+	return "diff --git a/" + path + " b/" + path + "\nindex 1111111..2222222 100644\n--- a/" + path + "\n+++ b/" + path +
+		"\n@@ -1 +1 @@\n" + strings.Join(lines, "\n") + "\n"
+}
+
+func TestFilterDiff(t *testing.T) {
+	dir, repo := newExecRepository(t)
+	useRepository(t, repo)
+
+	ignore := "# Generated docs\ndocs/\n\n!go.sum\n"
+	if err := os.WriteFile(filepath.Join(dir, IgnoreFile), []byte(ignore), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	diff := fileDiff("main.go", "-old", "+new") +
+		fileDiff("docs/api.md", "-old", "+new") +
+		fileDiff("go.sum", "-old", "+new") +
+		fileDiff("yarn.lock", "-old", "+new") +
+		fileDiff("logo.png", "-old", "+new")
+	changes := map[string]FileChange{
+		"main.go":  {Path: "main.go", Kind: KindText},
+		"logo.png": {Path: "logo.png", Kind: KindBinary, OldSize: 1024, NewSize: 2048},
+	}
+
+	got := FilterDiff(diff, changes)
+	want := fileDiff("main.go", "-old", "+new") +
+		"docs/api.md | 1 insertions(+), 1 deletions(-) (content omitted)\n" +
+		fileDiff("go.sum", "-old", "+new") +
+		"yarn.lock | 1 insertions(+), 1 deletions(-) (content omitted)\n" +
+		changes["logo.png"].Summary()
+	if got != want {
+		t.Errorf("FilterDiff() =\n%s\nwant\n%s", got, want)
+	}
+}
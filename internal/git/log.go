@@ -0,0 +1,81 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LogEntry is a single commit as reported by "git log".
+type LogEntry struct {
+	Hash    string
+	Subject string
+	Body    string
+}
+
+const (
+	logFieldSep  = "\x1f"
+	logRecordSep = "\x1e"
+)
+
+// GetLog returns the commits reachable from the given revision range,
+// newest first.
+func GetLog(revRange string) ([]LogEntry, error) {
+	return current.Log(revRange)
+}
+
+// FormatLog renders log entries as a short, human readable list.
+func FormatLog(entries []LogEntry) string {
+	var b strings.Builder
+	for _, e := range entries {
+		fmt.Fprintf(&b, "%s %s\n", shortHash(e.Hash), e.Subject)
+		if e.Body != "" {
+			for _, line := range strings.Split(e.Body, "\n") {
+				fmt.Fprintf(&b, "    %s\n", line)
+			}
+		}
+	}
+	return b.String()
+}
+
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
+
+// GetRangeDiff returns the diff between two revisions.
+func GetRangeDiff(from, to string) (string, error) {
+	if err := requireExec("git diff"); err != nil {
+		return "", err
+	}
+	cmd := command(append(append([]string{"--no-pager", "diff"}, diffPrefixes...), from, to)...)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git diff failed: %w", err)
+	}
+	return string(output), nil
+}
+
+// GetRangeStat returns the diffstat between two revisions.
+func GetRangeStat(from, to string) (string, error) {
+	if err := requireExec("git diff --stat"); err != nil {
+		return "", err
+	}
+	cmd := command("--no-pager", "diff", "--stat", from, to)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git diff failed: %w", err)
+	}
+	return string(output), nil
+}
+
+// GetRecentSubjects returns the subjects of the last n commits, newest
+// first, or nil when there are none.
+func GetRecentSubjects(n int) []string {
+	subjects, err := current.RecentSubjects(n)
+	if err != nil {
+		return nil
+	}
+	return subjects
+}
@@ -0,0 +1,106 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Backends selectable with COMMI_GIT_BACKEND.
+const (
+	BackendExec  = "exec"
+	BackendGoGit = "go-git"
+)
+
+var ErrNothingToCommit = errors.New("nothing to commit")
+
+// ErrExecOnly is returned by helpers that need the git binary when another
+// backend is in use.
+var ErrExecOnly = fmt.Errorf("only supported by the %s git backend", BackendExec)
+
+// Repository is the set of git operations the commit pipeline relies on.
+type Repository interface {
+	// Status returns the changes in "git status --porcelain" format, or
+	// ErrNothingToCommit when the working tree is clean.
+	Status() (string, error)
+	// Diff returns the unstaged diff of a single file.
+	Diff(file string, opts DiffOptions) (string, error)
+	// Diffs returns the unstaged diffs of several files at once, keyed by
+	// path. Files without changes may be missing.
+	Diffs(files []string, opts DiffOptions) (map[string]string, error)
+	// Log returns the commits in a revision range, newest first.
+	Log(revRange string) ([]LogEntry, error)
+	// Stage adds the given files to the index, or every change if none are
+	// given.
+	Stage(files ...string) error
+	// Commit stages what the options ask for and creates a commit.
+	Commit(opts CommitOptions) error
+	// CurrentBranch returns the name of the checked out branch, or HEAD
+	// when it is detached.
+	CurrentBranch() (string, error)
+	// RecentSubjects returns the subjects of the last n commits, newest
+	// first.
+	RecentSubjects(n int) ([]string, error)
+	// Config returns the value of a git config key, or an empty string if
+	// it isn't set.
+	Config(key string) string
+	// Root returns the top-level directory of the working tree.
+	Root() string
+}
+
+// current is the repository used by the package level helpers.
+var current Repository = &execRepository{}
+
+// Open returns the repository containing path, backed by the given
+// implementation.
+func Open(backend, path string) (Repository, error) {
+	if path == "" {
+		path = "."
+	}
+	switch backend {
+	case "", BackendExec:
+		root, err := FindRoot(path)
+		if err != nil {
+			return nil, err
+		}
+		return &execRepository{root: root}, nil
+	case BackendGoGit:
+		return OpenGoGit(path)
+	default:
+		return nil, fmt.Errorf("unknown git backend %q, expected %s or %s", backend, BackendExec, BackendGoGit)
+	}
+}
+
+// Use sets the repository used by the package level helpers.
+func Use(repo Repository) {
+	current = repo
+}
+
+// FindRoot returns the top-level directory of the repository containing
+// path.
+func FindRoot(path string) (string, error) {
+	output, err := exec.Command("git", "-C", path, "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return "", fmt.Errorf("not a git repository: %s", path)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// requireExec fails helpers that run the git binary directly unless the
+// exec backend is in use.
+func requireExec(op string) error {
+	if _, ok := current.(*execRepository); ok {
+		return nil
+	}
+	return fmt.Errorf("%s: %w", op, ErrExecOnly)
+}
+
+// command prepares a git invocation that runs in the root of the current
+// repository, so paths from porcelain output resolve from any subdirectory.
+// Helpers using it are exec-only and check requireExec first.
+func command(args ...string) *exec.Cmd {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = current.Root()
+	return cmd
+}
@@ -0,0 +1,99 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+const subprojectPrefix = "Subproject commit "
+
+// SubmoduleChange is a submodule pointer moved from one commit to another.
+type SubmoduleChange struct {
+	Path string
+	Old  string
+	New  string
+}
+
+// parseSubmoduleDiff recognizes the diff git produces for a submodule
+// pointer change. New may carry a "-dirty" suffix when the submodule has
+// local modifications.
+func parseSubmoduleDiff(path, diff string) (SubmoduleChange, bool) {
+	change := SubmoduleChange{Path: path}
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "-"+subprojectPrefix):
+			change.Old = strings.TrimPrefix(line, "-"+subprojectPrefix)
+		case strings.HasPrefix(line, "+"+subprojectPrefix):
+			change.New = strings.TrimPrefix(line, "+"+subprojectPrefix)
+		}
+	}
+	return change, change.Old != "" && change.New != ""
+}
+
+// describeSubmodules summarizes the pointer changes of the checked out
+// submodules among files, by path.
+func describeSubmodules(files []string) map[string]string {
+	summaries := make(map[string]string)
+	for _, file := range files {
+		if _, err := os.Stat(filepath.Join(current.Root(), file, ".git")); err != nil {
+			continue
+		}
+		diff, err := current.Diff(file, DefaultDiffOptions)
+		if err != nil {
+			log.Warn().Err(err).Str("file", file).Msg("Failed to get diff for submodule")
+			continue
+		}
+		change, ok := parseSubmoduleDiff(file, diff)
+		if !ok {
+			continue
+		}
+		summary, err := describeSubmodule(change)
+		if err != nil {
+			log.Warn().Err(err).Str("file", file).Msg("Failed to describe submodule change")
+			continue
+		}
+		summaries[file] = summary
+	}
+	return summaries
+}
+
+// describeSubmodule summarizes a submodule pointer change with the
+// submodule's own log, since the SHAs alone say nothing about the change.
+func describeSubmodule(change SubmoduleChange) (string, error) {
+	newRev := strings.TrimSuffix(change.New, "-dirty")
+	if newRev == change.Old {
+		return fmt.Sprintf("Submodule %s has uncommitted changes\n", change.Path), nil
+	}
+
+	sub, err := openSubmodule(filepath.Join(current.Root(), change.Path))
+	if err != nil {
+		return "", err
+	}
+	entries, err := sub.Log(change.Old + ".." + newRev)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Submodule %s %s..%s (%d commits):\n", change.Path, shortHash(change.Old), shortHash(newRev), len(entries))
+	for _, e := range entries {
+		fmt.Fprintf(&b, "  > %s\n", e.Subject)
+	}
+	if newRev != change.New {
+		fmt.Fprintf(&b, "Submodule %s has uncommitted changes\n", change.Path)
+	}
+	return b.String(), nil
+}
+
+// openSubmodule opens a checked out submodule with the same backend as the
+// current repository.
+func openSubmodule(path string) (Repository, error) {
+	if _, ok := current.(*goGitRepository); ok {
+		return OpenGoGit(path)
+	}
+	return &execRepository{root: path}, nil
+}
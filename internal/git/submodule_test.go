@@ -0,0 +1,45 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGetGitDiffsSubmodule(t *testing.T) {
+	dir, repo := newExecRepository(t)
+	useRepository(t, repo)
+
+	run := func(dir string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, output)
+		}
+	}
+	lib := filepath.Join(t.TempDir(), "lib")
+	run(t.TempDir(), "init", "-q", lib)
+	run(lib, "commit", "-q", "--allow-empty", "-m", "Initial commit")
+	run(dir, "-c", "protocol.file.allow=always", "submodule", "add", "-q", lib, "lib")
+	run(dir, "commit", "-q", "-m", "Add lib")
+
+	sub := filepath.Join(dir, "lib")
+	if err := os.WriteFile(filepath.Join(sub, "a.txt"), []byte("a\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	run(sub, "add", "a.txt")
+	run(sub, "commit", "-q", "-m", "Add a")
+
+	// The richer second collection must keep the summary
+	previous := AutoDiffBudget
+	AutoDiffBudget = 1 << 20
+	t.Cleanup(func() { AutoDiffBudget = previous })
+
+	diffs := GetGitDiffs([]string{"lib"})
+	if !strings.Contains(diffs, "(1 commits):\n  > Add a\n") {
+		t.Errorf("GetGitDiffs() misses the submodule log:\n%s", diffs)
+	}
+}
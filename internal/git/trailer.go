@@ -0,0 +1,32 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+)
+
+// InterpretTrailers adds trailers to a commit message the same way
+// "git interpret-trailers" does, skipping ones that are already present.
+func InterpretTrailers(message string, trailers []string) (string, error) {
+	if err := requireExec("git interpret-trailers"); err != nil {
+		return "", err
+	}
+	args := []string{"interpret-trailers", "--if-exists", "addIfDifferent"}
+	for _, t := range trailers {
+		args = append(args, "--trailer", t)
+	}
+
+	cmd := command(args...)
+	cmd.Stdin = strings.NewReader(message)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git interpret-trailers failed: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// GetConfig returns the value of a git config key, or an empty string if
+// it isn't set.
+func GetConfig(key string) string {
+	return current.Config(key)
+}
@@ -0,0 +1,159 @@
+// Package history keeps every generated commit message per repository, so
+// messages that were cancelled or regenerated can be found again.
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"commi/internal/config"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// MaxEntries is the number of generations kept per repository.
+const MaxEntries = 500
+
+// Action is what the user did with a generated message.
+type Action string
+
+const (
+	// ActionGenerated is a message no decision was recorded for, e.g. one
+	// printed in a non-interactive run.
+	ActionGenerated   Action = "generated"
+	ActionCommitted   Action = "committed"
+	ActionCopied      Action = "copied"
+	ActionRegenerated Action = "regenerated"
+	ActionCancelled   Action = "cancelled"
+)
+
+// Entry is one generated commit message.
+type Entry struct {
+	ID       string    `json:"id"`
+	Time     time.Time `json:"time"`
+	DiffHash string    `json:"diff_hash"`
+	Provider string    `json:"provider,omitempty"`
+	Model    string    `json:"model,omitempty"`
+	Title    string    `json:"title"`
+	// Body is everything after the title, including trailers.
+	Body   string `json:"body,omitempty"`
+	Action Action `json:"action"`
+}
+
+// Store is the history of one repository.
+type Store struct {
+	path string
+}
+
+// Open returns the history of the repository at root. Histories live in
+// the data directory, one file per repository.
+func Open(root string) (*Store, error) {
+	dir, err := config.DataDir()
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256([]byte(root))
+	return &Store{path: filepath.Join(dir, "history", hex.EncodeToString(sum[:8])+".jsonl")}, nil
+}
+
+// HashDiff identifies the changes a message was generated for.
+func HashDiff(diff string) string {
+	sum := sha256.Sum256([]byte(diff))
+	return hex.EncodeToString(sum[:])
+}
+
+// Add records a new entry and returns its ID. The oldest entries are
+// dropped beyond MaxEntries.
+func (s *Store) Add(e Entry) (string, error) {
+	e.Time = time.Now()
+	e.ID = fmt.Sprintf("%x", e.Time.UnixNano())
+	if e.Action == "" {
+		e.Action = ActionGenerated
+	}
+
+	entries, err := s.load()
+	if err != nil {
+		return "", err
+	}
+	entries = append(entries, e)
+	if len(entries) > MaxEntries {
+		entries = entries[len(entries)-MaxEntries:]
+	}
+	return e.ID, s.save(entries)
+}
+
+// SetAction records what the user did with an entry.
+func (s *Store) SetAction(id string, action Action) error {
+	entries, err := s.load()
+	if err != nil {
+		return err
+	}
+	for i := range entries {
+		if entries[i].ID == id {
+			entries[i].Action = action
+			return s.save(entries)
+		}
+	}
+	return fmt.Errorf("no history entry %s", id)
+}
+
+// List returns the entries, newest first.
+func (s *Store) List() ([]Entry, error) {
+	entries, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries, nil
+}
+
+func (s *Store) load() ([]Entry, error) {
+	content, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err == nil {
+			entries = append(entries, e)
+		}
+	}
+	return entries, scanner.Err()
+}
+
+// save replaces the history file, writing to a temporary file first so an
+// interrupted write doesn't lose the history.
+func (s *Store) save(entries []Entry) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	for _, e := range entries {
+		line, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		buf.Write(append(line, '\n'))
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
@@ -0,0 +1,221 @@
+// Package ledger keeps a local record of the tokens used per request, to
+// report usage and estimated costs over time.
+package ledger
+
+import (
+	"bufio"
+	"commi/internal/config"
+	"commi/internal/core"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const fileName = "usage.jsonl"
+
+// Entry is the usage of one operation, such as generating a commit message.
+type Entry struct {
+	Time         time.Time `json:"time"`
+	Provider     string    `json:"provider"`
+	Model        string    `json:"model"`
+	InputTokens  int       `json:"input_tokens"`
+	OutputTokens int       `json:"output_tokens"`
+	CachedTokens int       `json:"cached_tokens,omitempty"`
+}
+
+func path() (string, error) {
+	dir, err := config.DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, fileName), nil
+}
+
+// Record appends the usage to the ledger.
+func Record(u core.Usage) error {
+	p, err := path()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+
+	line, err := json.Marshal(Entry{
+		Time:         time.Now(),
+		Provider:     u.Provider,
+		Model:        u.Model,
+		InputTokens:  u.InputTokens,
+		OutputTokens: u.OutputTokens,
+		CachedTokens: u.CachedTokens,
+	})
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(p, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+// Load reads all entries of the ledger. A missing ledger has no entries;
+// lines that can't be parsed are skipped.
+func Load() ([]Entry, error) {
+	p, err := path()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err == nil {
+			entries = append(entries, e)
+		}
+	}
+	return entries, scanner.Err()
+}
+
+// Price is the cost of a model in USD per million tokens.
+type Price struct {
+	Input  float64
+	Output float64
+	// Cached is the price of input tokens read from the prompt cache.
+	Cached float64
+}
+
+// DefaultPrices are list prices at the time of writing, override them with
+// COMMI_PRICES. Models are matched by prefix, so dated versions share a
+// price.
+var DefaultPrices = map[string]Price{
+	"claude-3-7-sonnet": {Input: 3, Output: 15, Cached: 0.3},
+	"claude-3-5-sonnet": {Input: 3, Output: 15, Cached: 0.3},
+	"claude-3-5-haiku":  {Input: 0.8, Output: 4, Cached: 0.08},
+	"claude-3-opus":     {Input: 15, Output: 75, Cached: 1.5},
+	"gpt-4o-mini":       {Input: 0.15, Output: 0.6, Cached: 0.075},
+	"gpt-4o":            {Input: 2.5, Output: 10, Cached: 1.25},
+	"o3-mini":           {Input: 1.1, Output: 4.4, Cached: 0.55},
+}
+
+// ParsePrices adds prices given as "model=input/output[/cached]",
+// separated by ";", to the defaults.
+func ParsePrices(s string) (map[string]Price, error) {
+	prices := make(map[string]Price, len(DefaultPrices))
+	for model, p := range DefaultPrices {
+		prices[model] = p
+	}
+
+	for _, item := range strings.Split(s, ";") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		model, values, ok := strings.Cut(item, "=")
+		model = strings.TrimSpace(model)
+		parts := strings.Split(values, "/")
+		// An empty model would be the prefix of every model
+		if !ok || model == "" || len(parts) < 2 || len(parts) > 3 {
+			return nil, fmt.Errorf("invalid price %q, expected model=input/output[/cached]", item)
+		}
+		var numbers [3]float64
+		for i, part := range parts {
+			n, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid price %q: %w", item, err)
+			}
+			numbers[i] = n
+		}
+		if len(parts) == 2 {
+			numbers[2] = numbers[0]
+		}
+		prices[model] = Price{Input: numbers[0], Output: numbers[1], Cached: numbers[2]}
+	}
+	return prices, nil
+}
+
+// priceFor finds the price of the longest model name that model starts
+// with.
+func priceFor(prices map[string]Price, model string) (Price, bool) {
+	best, found := "", false
+	for name := range prices {
+		if strings.HasPrefix(model, name) && len(name) >= len(best) {
+			best, found = name, true
+		}
+	}
+	return prices[best], found
+}
+
+// Cost estimates the cost of an entry in USD.
+func (p Price) Cost(e Entry) float64 {
+	uncached := e.InputTokens - e.CachedTokens
+	return (float64(uncached)*p.Input + float64(e.CachedTokens)*p.Cached + float64(e.OutputTokens)*p.Output) / 1e6
+}
+
+// Total sums the usage of a provider on one day.
+type Total struct {
+	Day          string
+	Provider     string
+	InputTokens  int
+	OutputTokens int
+	CachedTokens int
+	Cost         float64
+	// Unpriced is set when some of the usage is of a model without a
+	// price, so Cost is incomplete.
+	Unpriced bool
+}
+
+// Totals sums the entries since the given time per day and provider,
+// ordered by day.
+func Totals(entries []Entry, prices map[string]Price, since time.Time) []Total {
+	byKey := make(map[[2]string]*Total)
+	for _, e := range entries {
+		if e.Time.Before(since) {
+			continue
+		}
+		key := [2]string{e.Time.Local().Format("2006-01-02"), e.Provider}
+		t, ok := byKey[key]
+		if !ok {
+			t = &Total{Day: key[0], Provider: key[1]}
+			byKey[key] = t
+		}
+		t.InputTokens += e.InputTokens
+		t.OutputTokens += e.OutputTokens
+		t.CachedTokens += e.CachedTokens
+		if p, ok := priceFor(prices, e.Model); ok {
+			t.Cost += p.Cost(e)
+		} else {
+			t.Unpriced = true
+		}
+	}
+
+	totals := make([]Total, 0, len(byKey))
+	for _, t := range byKey {
+		totals = append(totals, *t)
+	}
+	sort.Slice(totals, func(i, j int) bool {
+		if totals[i].Day != totals[j].Day {
+			return totals[i].Day < totals[j].Day
+		}
+		return totals[i].Provider < totals[j].Provider
+	})
+	return totals
+}
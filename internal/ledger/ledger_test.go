@@ -0,0 +1,120 @@
+package ledger
+
+import (
+	"commi/internal/core"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParsePrices(t *testing.T) {
+	prices, err := ParsePrices("my-model=1/2; claude-3-opus = 10/50/1 ;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := prices["my-model"], (Price{Input: 1, Output: 2, Cached: 1}); got != want {
+		t.Errorf("my-model price = %+v, want %+v", got, want)
+	}
+	if got, want := prices["claude-3-opus"], (Price{Input: 10, Output: 50, Cached: 1}); got != want {
+		t.Errorf("claude-3-opus price = %+v, want %+v", got, want)
+	}
+	if got := prices["gpt-4o"]; got != DefaultPrices["gpt-4o"] {
+		t.Errorf("gpt-4o price = %+v, want the default", got)
+	}
+	if DefaultPrices["claude-3-opus"].Input != 15 {
+		t.Error("ParsePrices() changed DefaultPrices")
+	}
+}
+
+func TestParsePricesInvalid(t *testing.T) {
+	for _, s := range []string{
+		"my-model",
+		"my-model=1",
+		"my-model=1/2/3/4",
+		"my-model=1/two",
+		"=1/2",
+	} {
+		if _, err := ParsePrices(s); err == nil {
+			t.Errorf("ParsePrices(%q) has no error", s)
+		}
+	}
+}
+
+func TestTotals(t *testing.T) {
+	day := time.Date(2025, 3, 10, 12, 0, 0, 0, time.Local)
+	entries := []Entry{
+		{Time: day.AddDate(0, 0, -10), Provider: "ANTHROPIC", Model: "claude-3-5-haiku", InputTokens: 1_000_000},
+		{Time: day, Provider: "OPENAI", Model: "gpt-4o-2024-08-06", InputTokens: 1_000_000, OutputTokens: 100_000},
+		{Time: day, Provider: "ANTHROPIC", Model: "claude-3-5-haiku-20241022", InputTokens: 1_000_000, CachedTokens: 500_000},
+		{Time: day.Add(time.Hour), Provider: "ANTHROPIC", Model: "claude-unknown", InputTokens: 10, OutputTokens: 5},
+		{Time: day.AddDate(0, 0, 1), Provider: "OPENAI", Model: "gpt-4o-mini", OutputTokens: 1_000_000},
+	}
+
+	totals := Totals(entries, DefaultPrices, day.AddDate(0, 0, -1))
+	want := []Total{
+		{Day: "2025-03-10", Provider: "ANTHROPIC", InputTokens: 1_000_010, OutputTokens: 5, CachedTokens: 500_000, Cost: 0.44, Unpriced: true},
+		{Day: "2025-03-10", Provider: "OPENAI", InputTokens: 1_000_000, OutputTokens: 100_000, Cost: 3.5},
+		{Day: "2025-03-11", Provider: "OPENAI", OutputTokens: 1_000_000, Cost: 0.6},
+	}
+	if len(totals) != len(want) {
+		t.Fatalf("Totals() = %+v, want %+v", totals, want)
+	}
+	for i := range want {
+		got := totals[i]
+		if math.Abs(got.Cost-want[i].Cost) > 1e-9 {
+			t.Errorf("Totals()[%d].Cost = %f, want %f", i, got.Cost, want[i].Cost)
+		}
+		got.Cost = want[i].Cost
+		if got != want[i] {
+			t.Errorf("Totals()[%d] = %+v, want %+v", i, got, want[i])
+		}
+	}
+}
+
+func TestRecordLoad(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dir)
+
+	if entries, err := Load(); err != nil || entries != nil {
+		t.Fatalf("Load() of a missing ledger = %v, %v, want nothing", entries, err)
+	}
+
+	usages := []core.Usage{
+		{Provider: "ANTHROPIC", Model: "claude-3-5-haiku", InputTokens: 100, OutputTokens: 20, CachedTokens: 50},
+		{Provider: "OPENAI", Model: "gpt-4o", InputTokens: 200, OutputTokens: 40},
+	}
+	for _, u := range usages {
+		if err := Record(u); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Lines that can't be parsed are skipped
+	p := filepath.Join(dir, "commi", fileName)
+	f, err := os.OpenFile(p, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString("{not json\n"); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	entries, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []core.Usage
+	for _, e := range entries {
+		got = append(got, core.Usage{Provider: e.Provider, Model: e.Model, InputTokens: e.InputTokens, OutputTokens: e.OutputTokens, CachedTokens: e.CachedTokens})
+		if time.Since(e.Time) > time.Minute {
+			t.Errorf("entry time = %v, want now", e.Time)
+		}
+	}
+	if !reflect.DeepEqual(got, usages) {
+		t.Errorf("Load() = %+v, want %+v", got, usages)
+	}
+}
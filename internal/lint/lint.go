@@ -0,0 +1,197 @@
+// Package lint checks commit messages against the conventions commi asks
+// the model to follow, and fixes what can be fixed without changing their
+// meaning.
+package lint
+
+import (
+	"commi/internal/core"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Message is a commit message split into its title, body and trailers.
+type Message struct {
+	Title string
+	Body  string
+	// Trailers are "Key: Value" lines such as "Refs: CR-22".
+	Trailers []string
+	// joined is set when the body directly follows the title, without the
+	// blank line git expects.
+	joined bool
+}
+
+// Parse reads a commit message as git would: comment lines are dropped and
+// everything below a scissors line is ignored.
+func Parse(text string) Message {
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		if strings.HasPrefix(line, "# ") && strings.Contains(line, ">8") {
+			break
+		}
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, strings.TrimRight(line, " \t"))
+	}
+	for len(lines) > 0 && lines[0] == "" {
+		lines = lines[1:]
+	}
+	if len(lines) == 0 {
+		return Message{}
+	}
+
+	m := Message{Title: lines[0]}
+	rest := lines[1:]
+	if len(rest) > 0 && rest[0] != "" {
+		m.joined = true
+	}
+	m.Body = strings.Trim(strings.Join(rest, "\n"), "\n")
+
+	// A last paragraph made only of trailers holds the trailers
+	paragraphs := strings.Split(m.Body, "\n\n")
+	last := strings.Split(paragraphs[len(paragraphs)-1], "\n")
+	for _, line := range last {
+		if match := trailerLine.FindStringSubmatch(line); match == nil || !core.IsTrailerKey(match[1]) {
+			return m
+		}
+	}
+	m.Trailers = last
+	m.Body = strings.TrimRight(strings.Join(paragraphs[:len(paragraphs)-1], "\n\n"), "\n")
+	if m.Body == "" {
+		m.joined = false
+	}
+	return m
+}
+
+var trailerLine = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9-]*): \S`)
+
+// String renders the message the way it is committed.
+func (m Message) String() string {
+	text := m.Title + "\n"
+	if m.Body != "" {
+		if !m.joined {
+			text += "\n"
+		}
+		text += m.Body + "\n"
+	}
+	if len(m.Trailers) > 0 {
+		text += "\n" + strings.Join(m.Trailers, "\n") + "\n"
+	}
+	return text
+}
+
+// Options configure the rules. Zero values use the defaults.
+type Options struct {
+	// TitleLength is the maximum title length, 72 by default.
+	TitleLength int
+	// BodyWidth is the column at which body lines wrap, 72 by default.
+	BodyWidth int
+	// ForbiddenWords may not appear in the title or body, case-insensitive.
+	ForbiddenWords []string
+	// RequireTicket reports messages without a ticket ID.
+	RequireTicket bool
+	// TicketPatterns find ticket IDs, see core.ExtractTickets.
+	TicketPatterns []string
+	// Disabled lists the names of rules to skip, "all" skips every rule.
+	Disabled []string
+}
+
+// DefaultWidth is the default title length and body width.
+const DefaultWidth = 72
+
+func (o Options) titleLength() int {
+	if o.TitleLength > 0 {
+		return o.TitleLength
+	}
+	return DefaultWidth
+}
+
+func (o Options) bodyWidth() int {
+	if o.BodyWidth > 0 {
+		return o.BodyWidth
+	}
+	return DefaultWidth
+}
+
+func (o Options) enabled(name string) bool {
+	for _, d := range o.Disabled {
+		if d == name || d == "all" {
+			return false
+		}
+	}
+	return true
+}
+
+// Issue is a problem found by a rule.
+type Issue struct {
+	Rule    string
+	Message string
+	// Fixed is set when the problem was corrected by the rule's fix.
+	Fixed bool
+}
+
+func (i Issue) String() string {
+	if i.Fixed {
+		return fmt.Sprintf("%s: %s (fixed)", i.Rule, i.Message)
+	}
+	return fmt.Sprintf("%s: %s", i.Rule, i.Message)
+}
+
+// Remaining counts the issues that weren't fixed.
+func Remaining(issues []Issue) int {
+	n := 0
+	for _, i := range issues {
+		if !i.Fixed {
+			n++
+		}
+	}
+	return n
+}
+
+// Rule checks one convention. Fix is nil for rules that can't be fixed
+// automatically.
+type Rule struct {
+	Name  string
+	Check func(m Message, opts Options) []string
+	Fix   func(m *Message, opts Options)
+}
+
+// Rules are run in order, so fixes that change the title come before the
+// checks that depend on it.
+var Rules = []Rule{
+	{Name: "title-period", Check: checkTitlePeriod, Fix: fixTitlePeriod},
+	// Rewording the title can change its meaning, e.g. "Logging
+	// improvements", so title-imperative only warns
+	{Name: "title-imperative", Check: checkImperative},
+	{Name: "title-length", Check: checkTitleLength},
+	{Name: "blank-line", Check: checkBlankLine, Fix: fixBlankLine},
+	{Name: "body-width", Check: checkBodyWidth, Fix: fixBodyWidth},
+	{Name: "forbidden-words", Check: checkForbiddenWords},
+	{Name: "ticket", Check: checkTicket},
+}
+
+// Lint checks the message against every enabled rule. With fix, fixable
+// problems are corrected and reported as Fixed, along with the remaining
+// ones.
+func Lint(m Message, opts Options, fix bool) (Message, []Issue) {
+	var issues []Issue
+	for _, r := range Rules {
+		if !opts.enabled(r.Name) {
+			continue
+		}
+		found := r.Check(m, opts)
+		if fix && r.Fix != nil && len(found) > 0 {
+			r.Fix(&m, opts)
+			remaining := r.Check(m, opts)
+			if len(remaining) < len(found) {
+				issues = append(issues, Issue{Rule: r.Name, Message: found[0], Fixed: true})
+			}
+			found = remaining
+		}
+		for _, msg := range found {
+			issues = append(issues, Issue{Rule: r.Name, Message: msg})
+		}
+	}
+	return m, issues
+}
@@ -0,0 +1,98 @@
+package lint
+
+import (
+	"testing"
+)
+
+func TestImperative(t *testing.T) {
+	tests := []struct {
+		word string
+		want string
+		ok   bool
+	}{
+		{word: "Added", want: "Add", ok: true},
+		{word: "fixed", want: "fix", ok: true},
+		{word: "removes", want: "remove", ok: true},
+		{word: "Formatted", want: "Format", ok: true},
+		{word: "formatting", want: "format", ok: true},
+		{word: "Dropped", want: "Drop", ok: true},
+		{word: "Logging", want: "Log", ok: true},
+		{word: "Updates"},
+		{word: "formated"},
+		{word: "Add"},
+	}
+	for _, tt := range tests {
+		got, ok := imperative(tt.word)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("imperative(%q) = %q, %t, want %q, %t", tt.word, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestLintFix(t *testing.T) {
+	tests := []struct {
+		name      string
+		message   string
+		title     string
+		fixed     []string
+		remaining []string
+	}{
+		{
+			name:      "imperative only warns",
+			message:   "Logging improvements for the parser\n",
+			title:     "Logging improvements for the parser",
+			remaining: []string{"title-imperative"},
+		},
+		{
+			name:      "past tense keeps its meaning",
+			message:   "Used by default\n",
+			title:     "Used by default",
+			remaining: []string{"title-imperative"},
+		},
+		{
+			name:    "period is fixed and reported",
+			message: "Add retries.\n",
+			title:   "Add retries",
+			fixed:   []string{"title-period"},
+		},
+		{
+			name:    "clean message",
+			message: "Add retries\n\nRetry failed uploads.\n",
+			title:   "Add retries",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, issues := Lint(Parse(tt.message), Options{}, true)
+			if m.Title != tt.title {
+				t.Errorf("title = %q, want %q", m.Title, tt.title)
+			}
+			var fixed, remaining []string
+			for _, i := range issues {
+				if i.Fixed {
+					fixed = append(fixed, i.Rule)
+				} else {
+					remaining = append(remaining, i.Rule)
+				}
+			}
+			if !equal(fixed, tt.fixed) || !equal(remaining, tt.remaining) {
+				t.Errorf("issues = %v, want fixed %v and remaining %v", issues, tt.fixed, tt.remaining)
+			}
+			if Remaining(issues) != len(tt.remaining) {
+				t.Errorf("Remaining() = %d, want %d", Remaining(issues), len(tt.remaining))
+			}
+		})
+	}
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
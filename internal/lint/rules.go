@@ -0,0 +1,234 @@
+package lint
+
+import (
+	"commi/internal/core"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+func checkTitleLength(m Message, opts Options) []string {
+	if n := utf8.RuneCountInString(m.Title); n > opts.titleLength() {
+		return []string{fmt.Sprintf("title is %d characters long, keep it under %d", n, opts.titleLength())}
+	}
+	return nil
+}
+
+func checkTitlePeriod(m Message, _ Options) []string {
+	if strings.HasSuffix(m.Title, ".") && !strings.HasSuffix(m.Title, "...") {
+		return []string{"title ends with a period"}
+	}
+	return nil
+}
+
+func fixTitlePeriod(m *Message, _ Options) {
+	if !strings.HasSuffix(m.Title, "...") {
+		m.Title = strings.TrimRight(strings.TrimSuffix(m.Title, "."), " ")
+	}
+}
+
+func checkImperative(m Message, _ Options) []string {
+	word, _ := titleVerb(m.Title)
+	if base, ok := imperative(word); ok {
+		return []string{fmt.Sprintf("use the imperative mood in the title, %q instead of %q", base, word)}
+	}
+	return nil
+}
+
+// imperative returns the imperative of a past tense, third person or
+// gerund verb, capitalized like the word.
+func imperative(word string) (string, bool) {
+	base, ok := nonImperative[strings.ToLower(word)]
+	if !ok {
+		return "", false
+	}
+	if r, _ := utf8.DecodeRuneInString(word); unicode.IsUpper(r) {
+		base = strings.ToUpper(base[:1]) + base[1:]
+	}
+	return base, true
+}
+
+// titleVerb finds the word the title should start with, skipping prefixes
+// such as "api:", "feat(api):", emoji and ticket IDs, and returns it with
+// its byte offset.
+func titleVerb(title string) (string, int) {
+	offset := 0
+	for _, field := range strings.Fields(title) {
+		start := offset + strings.Index(title[offset:], field)
+		offset = start + len(field)
+		switch {
+		case strings.HasSuffix(field, ":"),
+			!strings.ContainsFunc(field, unicode.IsLetter),
+			strings.ContainsFunc(field, unicode.IsDigit),
+			strings.HasPrefix(field, "["), strings.HasPrefix(field, "("):
+			continue
+		}
+		return strings.TrimRightFunc(field, func(r rune) bool { return !unicode.IsLetter(r) }), start
+	}
+	return "", 0
+}
+
+// imperativeVerbs are verbs commit titles commonly start with. Their past
+// tense, third person and gerund forms are reported by title-imperative.
+var imperativeVerbs = []string{
+	"add", "adjust", "allow", "avoid", "bump", "change", "clean", "clarify",
+	"configure", "convert", "correct", "create", "delete", "deprecate",
+	"disable", "document", "drop", "enable", "ensure", "extract", "fix",
+	"format", "handle", "hide", "implement", "improve", "include",
+	"increase", "introduce", "limit", "load", "log", "merge", "migrate",
+	"move", "optimize", "prevent", "refactor", "release", "remove",
+	"rename", "reorder", "replace", "restore", "revert", "rewrite", "show",
+	"simplify", "skip", "sort", "stop", "support", "switch", "test", "tidy",
+	"update", "upgrade", "use", "validate", "wrap",
+}
+
+// irregularForms lists non-imperative forms the suffix rules get wrong.
+var irregularForms = map[string]string{
+	"rewrote": "rewrite", "rewritten": "rewrite", "hid": "hide", "hidden": "hide",
+	"shown": "show", "made": "make", "makes": "make", "making": "make",
+	"wrote": "write", "writes": "write", "writing": "write",
+}
+
+// nounForms are third person forms that more often start a title as a
+// noun, as in "Changes to the parser".
+var nounForms = []string{"changes", "fixes", "logs", "merges", "releases", "tests", "updates"}
+
+// doubledVerbs double their final consonant before -ed and -ing. Spelling
+// follows stress, so they are listed rather than guessed.
+var doubledVerbs = []string{"drop", "format", "log", "skip", "stop", "wrap"}
+
+var nonImperative = inflect(imperativeVerbs, doubledVerbs, irregularForms, nounForms)
+
+// inflect builds a map from the past tense, third person and gerund of each
+// verb to the verb itself, leaving out the excluded forms.
+func inflect(verbs, doubled []string, irregular map[string]string, exclude []string) map[string]string {
+	double := make(map[string]bool, len(doubled))
+	for _, v := range doubled {
+		double[v] = true
+	}
+
+	forms := make(map[string]string)
+	for _, v := range verbs {
+		stem := v
+		if double[v] {
+			stem = v + v[len(v)-1:]
+		}
+
+		switch {
+		case strings.HasSuffix(v, "e"):
+			forms[v+"d"] = v
+			forms[v[:len(v)-1]+"ing"] = v
+		case strings.HasSuffix(v, "y") && !isVowel(v[len(v)-2]):
+			forms[v[:len(v)-1]+"ied"] = v
+			forms[v+"ing"] = v
+		default:
+			forms[stem+"ed"] = v
+			forms[stem+"ing"] = v
+		}
+
+		switch {
+		case strings.HasSuffix(v, "y") && !isVowel(v[len(v)-2]):
+			forms[v[:len(v)-1]+"ies"] = v
+		case strings.HasSuffix(v, "s"), strings.HasSuffix(v, "x"), strings.HasSuffix(v, "z"),
+			strings.HasSuffix(v, "ch"), strings.HasSuffix(v, "sh"):
+			forms[v+"es"] = v
+		default:
+			forms[v+"s"] = v
+		}
+	}
+	for form, v := range irregular {
+		forms[form] = v
+	}
+	for _, form := range exclude {
+		delete(forms, form)
+	}
+	return forms
+}
+
+func isVowel(b byte) bool {
+	return strings.IndexByte("aeiou", b) != -1
+}
+
+func checkBlankLine(m Message, _ Options) []string {
+	if m.joined {
+		return []string{"separate the title from the body with a blank line"}
+	}
+	return nil
+}
+
+func fixBlankLine(m *Message, _ Options) {
+	m.joined = false
+}
+
+func checkBodyWidth(m Message, opts Options) []string {
+	long := 0
+	forEachWrappable(m.Body, func(line string) string {
+		// Lines that only overflow because of a single long word, such
+		// as a URL, can't be wrapped
+		if len(core.WrapLine(line, opts.bodyWidth())) > 1 {
+			long++
+		}
+		return line
+	})
+	if long > 0 {
+		return []string{fmt.Sprintf("%d body lines are longer than %d characters", long, opts.bodyWidth())}
+	}
+	return nil
+}
+
+func fixBodyWidth(m *Message, opts Options) {
+	m.Body = forEachWrappable(m.Body, func(line string) string {
+		return strings.Join(core.WrapLine(line, opts.bodyWidth()), "\n")
+	})
+}
+
+// forEachWrappable calls f for each body line that may be wrapped and
+// replaces it with the result. Code blocks and indented code are left
+// alone.
+func forEachWrappable(body string, f func(string) string) string {
+	if body == "" {
+		return body
+	}
+	lines := strings.Split(body, "\n")
+	fenced := false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "```"):
+			fenced = !fenced
+		case fenced, strings.HasPrefix(line, "    "), strings.HasPrefix(line, "\t"):
+		default:
+			lines[i] = f(line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func checkForbiddenWords(m Message, opts Options) []string {
+	text := m.Title + "\n" + m.Body
+	var found []string
+	for _, word := range opts.ForbiddenWords {
+		re, err := regexp.Compile(`(?i)(?:^|\W)` + regexp.QuoteMeta(word) + `(?:\W|$)`)
+		if err == nil && re.MatchString(text) {
+			found = append(found, fmt.Sprintf("%q is not allowed", word))
+		}
+	}
+	return found
+}
+
+func checkTicket(m Message, opts Options) []string {
+	if !opts.RequireTicket {
+		return nil
+	}
+	text := m.Title + "\n" + m.Body + "\n" + strings.Join(m.Trailers, "\n")
+	tickets, err := core.ExtractTickets(text, opts.TicketPatterns)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	if len(tickets) == 0 {
+		return []string{"reference a ticket in the title, body or a trailer"}
+	}
+	return nil
+}
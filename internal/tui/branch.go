@@ -0,0 +1,157 @@
+package tui
+
+import (
+	"commi/internal/config"
+	"commi/internal/core"
+	"commi/internal/git"
+	"commi/internal/utils"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+type branchItem string
+
+func (i branchItem) FilterValue() string { return string(i) }
+
+type branchModel struct {
+	list     list.Model
+	choice   string
+	quitting bool
+}
+
+func (m branchModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m branchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.list.SetWidth(msg.Width)
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "ctrl+c":
+			m.quitting = true
+			return m, tea.Quit
+
+		case "enter":
+			if i, ok := m.list.SelectedItem().(branchItem); ok {
+				m.choice = string(i)
+			}
+			return m, tea.Quit
+		}
+	}
+
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+	return m, cmd
+}
+
+func (m branchModel) View() string {
+	if m.quitting {
+		return quitTextStyle.Render("Exiting...")
+	}
+	return "\n" + m.list.View()
+}
+
+// ===== BRANCH NAME SUGGESTION
+
+func RunBranch(cmd *cobra.Command, args []string, c *core.Core) {
+	var subject string
+	if len(args) > 0 {
+		subject = args[0]
+	}
+
+	// Current changes are optional, a subject alone is enough to name a branch
+	status, diffs, err := git.GetGitInfo()
+	if err != nil && !errors.Is(err, git.ErrNothingToCommit) {
+		log.Error().Err(err).Msg("Failed to get git information")
+		os.Exit(1)
+	}
+	if diffs == "" && subject == "" {
+		fmt.Println("No changes or subject to name a branch after. Make some changes or pass a subject.")
+		return
+	}
+
+	cfg := config.Load()
+
+	var ticket string
+	tickets, err := core.ExtractTickets(subject, cfg.TicketPatterns)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to extract tickets from subject")
+	} else if len(tickets) > 0 {
+		ticket = tickets[0]
+	}
+
+	diffs = redactOrExit(diffs)
+
+	spinner := NewSpinner()
+	spinner.Start("Generating branch names...")
+	names, err := c.GenerateBranchNames(context.Background(), core.BranchOptions{
+		Status:  status,
+		Diffs:   diffs,
+		Subject: subject,
+		Ticket:  ticket,
+		Pattern: cfg.BranchPattern,
+	})
+	spinner.Stop()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to generate branch names")
+		os.Exit(1)
+	}
+
+	forceFlag, _ := cmd.Flags().GetBool("force")
+	choice := names[0]
+	if !forceFlag {
+		if !utils.IsTTY() {
+			fmt.Println("Suggested branch names:")
+			for _, name := range names {
+				fmt.Printf("  %s\n", name)
+			}
+			fmt.Println("\nRun with -f flag to create the first branch automatically in non-interactive environments.")
+			return
+		}
+
+		items := make([]list.Item, len(names))
+		for i, name := range names {
+			items[i] = branchItem(name)
+		}
+
+		const defaultWidth = 30
+
+		l := list.New(items, itemDelegate{}, defaultWidth, listHeight)
+		l.Title = "Create branch"
+		l.SetShowStatusBar(false)
+		l.SetFilteringEnabled(false)
+		l.Styles.Title = titleStyle
+		l.Styles.PaginationStyle = paginationStyle
+		l.Styles.HelpStyle = helpStyle
+
+		p := tea.NewProgram(branchModel{list: l}, tea.WithAltScreen())
+		finalModel, err := p.Run()
+		if err != nil {
+			log.Error().Err(err).Msg("Error running Bubble Tea program")
+			os.Exit(1)
+		}
+		m, ok := finalModel.(branchModel)
+		if !ok || m.choice == "" {
+			log.Info().Msg("Branch creation aborted.")
+			return
+		}
+		choice = m.choice
+	}
+
+	if err := git.CreateBranch(choice); err != nil {
+		log.Error().Err(err).Msg("Failed to create branch")
+		os.Exit(1)
+	}
+	fmt.Printf("Switched to a new branch '%s'\n", choice)
+}
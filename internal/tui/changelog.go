@@ -0,0 +1,119 @@
+package tui
+
+import (
+	"commi/internal/core"
+	"commi/internal/git"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+const changelogHeader = `# Changelog
+
+All notable changes to this project will be documented in this file.
+
+The format is based on [Keep a Changelog](https://keepachangelog.com/en/1.1.0/).
+
+`
+
+// writeChangelog adds a release section to an existing changelog, merging
+// it into a section of the same release, or creates a new one.
+func writeChangelog(path, release, date string, entries []core.ChangelogEntry) error {
+	content, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	var result string
+	if len(content) == 0 {
+		result = changelogHeader + core.RenderChangelog(release, date, entries)
+	} else {
+		result = core.InsertChangelog(string(content), release, date, entries)
+	}
+	return os.WriteFile(path, []byte(result), 0o644)
+}
+
+// ===== CHANGELOG GENERATION
+
+func RunChangelog(cmd *cobra.Command, args []string, c *core.Core) {
+	revRange := args[0]
+	if !strings.Contains(revRange, "..") {
+		revRange += "..HEAD"
+	}
+
+	release, _ := cmd.Flags().GetString("release")
+	write, _ := cmd.Flags().GetBool("write")
+	file, _ := cmd.Flags().GetString("file")
+
+	logEntries, err := git.GetLog(revRange)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get commit log")
+		os.Exit(1)
+	}
+
+	var entries []core.ChangelogEntry
+	var unknown []int
+	for _, e := range logEntries {
+		if strings.HasPrefix(e.Subject, "Merge ") {
+			continue
+		}
+		entry, ok := core.ClassifyCommit(e.Subject)
+		if !ok {
+			unknown = append(unknown, len(entries))
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) == 0 {
+		fmt.Fprintf(os.Stderr, "No commits in %s.\n", revRange)
+		return
+	}
+
+	// Fall back to the model for commits without a recognizable prefix
+	if len(unknown) > 0 {
+		subjects := make([]string, len(unknown))
+		for i, idx := range unknown {
+			subjects[i] = entries[idx].Description
+		}
+
+		spinner := NewSpinner()
+		spinner.Start(fmt.Sprintf("Classifying %d commits...", len(unknown)))
+		types, err := c.ClassifyCommits(context.Background(), subjects)
+		spinner.Stop()
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to classify commits")
+			os.Exit(1)
+		}
+		for i, idx := range unknown {
+			entries[idx].Type = types[i]
+		}
+	}
+
+	var date string
+	if release != "Unreleased" {
+		date = time.Now().Format("2006-01-02")
+	}
+	if !write {
+		fmt.Print(core.RenderChangelog(release, date, entries))
+		return
+	}
+
+	if !filepath.IsAbs(file) {
+		root, err := git.GetRepoRoot()
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to find repository root")
+			os.Exit(1)
+		}
+		file = filepath.Join(root, file)
+	}
+	if err := writeChangelog(file, release, date, entries); err != nil {
+		log.Error().Err(err).Msgf("Failed to write %s", file)
+		os.Exit(1)
+	}
+	log.Info().Msgf("Changelog written to %s.", file)
+}
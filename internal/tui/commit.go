@@ -1,6 +1,172 @@
 package tui
 
+import (
+	"commi/internal/config"
+	"commi/internal/core"
+	"commi/internal/git"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
 type Commit struct {
-	Title   string
-	Message string
+	Title    string
+	Message  string
+	Trailers []core.Trailer
+	// Warnings are shown next to the message but never committed.
+	Warnings []string
+	// historyID is the message's entry in the history, if it was recorded.
+	historyID string
+}
+
+// Text returns the full commit message with the trailers appended.
+func (c *Commit) Text() string {
+	message := git.FormatMessage(c.Title, c.Message)
+	if len(c.Trailers) == 0 {
+		return message
+	}
+
+	trailers := make([]string, len(c.Trailers))
+	for i, t := range c.Trailers {
+		trailers[i] = t.String()
+	}
+
+	text, err := git.InterpretTrailers(message, trailers)
+	if err != nil {
+		if !errors.Is(err, git.ErrExecOnly) {
+			log.Warn().Err(err).Msg("Failed to interpret trailers, appending them as is")
+		}
+		return strings.TrimRight(message, "\n") + "\n\n" + strings.Join(trailers, "\n") + "\n"
+	}
+	return text + "\n"
+}
+
+// commitOptions are the user supplied settings applied to every generated
+// commit message.
+type commitOptions struct {
+	subject  string
+	prefix   string
+	trailers []core.Trailer
+	signoff  bool
+	cleanup  string
+	// scope limits the commit to a directory, relative to the repository
+	// root.
+	scope string
+	// gitArgs are passed through to git commit (everything after "--").
+	gitArgs []string
+	// noCache generates a new message even if one is cached.
+	noCache bool
+}
+
+func commitOptionsFromFlags(cmd *cobra.Command, args []string) (commitOptions, error) {
+	var opts commitOptions
+	if dash := cmd.ArgsLenAtDash(); dash != -1 {
+		opts.gitArgs = args[dash:]
+		args = args[:dash]
+	}
+	if len(args) > 0 {
+		opts.subject = args[0]
+	}
+	opts.prefix, _ = cmd.Flags().GetString("prefix")
+	opts.signoff, _ = cmd.Flags().GetBool("signoff")
+	opts.cleanup, _ = cmd.Flags().GetString("cleanup")
+	opts.scope, _ = cmd.Flags().GetString("scope")
+	opts.noCache, _ = cmd.Flags().GetBool("no-cache")
+	if opts.prefix == "" && opts.scope != "" {
+		opts.prefix = scopePrefix(opts.scope)
+	}
+
+	trailers, _ := cmd.Flags().GetStringArray("trailer")
+	for _, s := range trailers {
+		t, err := core.ParseTrailer(s)
+		if err != nil {
+			return opts, err
+		}
+		opts.trailers = append(opts.trailers, t)
+	}
+	return opts, nil
+}
+
+// scopePrefix derives the commit prefix from a scope directory, e.g.
+// "billing:" for services/billing.
+func scopePrefix(scope string) string {
+	name := filepath.Base(filepath.Clean(scope))
+	if name == "." || name == string(filepath.Separator) {
+		return ""
+	}
+	return name + ":"
+}
+
+// applyTrailers adds the configured automatic trailers and the ones given
+// on the command line to the commit.
+func applyTrailers(cfg *config.Config, commit *Commit, opts commitOptions) {
+	signoff := opts.signoff
+	for _, s := range cfg.Trailers {
+		if s == config.TrailerSignoff {
+			signoff = true
+			continue
+		}
+		t, err := core.ParseTrailer(s)
+		if err != nil {
+			log.Warn().Err(err).Msg("Skipping invalid trailer from COMMI_TRAILERS")
+			continue
+		}
+		commit.Trailers = append(commit.Trailers, t)
+	}
+
+	commit.Trailers = append(commit.Trailers, opts.trailers...)
+
+	if signoff {
+		name, email := git.GetConfig("user.name"), git.GetConfig("user.email")
+		if name == "" || email == "" {
+			log.Warn().Msg("Cannot sign off, user.name or user.email is not set in git config")
+			return
+		}
+		commit.Trailers = append(commit.Trailers, core.Trailer{
+			Key:   "Signed-off-by",
+			Value: fmt.Sprintf("%s <%s>", name, email),
+		})
+	}
+}
+
+// commitTarget describes how a generated message gets committed.
+type commitTarget struct {
+	// staged commits only what is already in the index.
+	staged bool
+	// files limits the commit to these paths, e.g. the ones in scope.
+	files   []string
+	cleanup string
+	gitArgs []string
+}
+
+func (t commitTarget) apply(commit *Commit) error {
+	return git.ExecuteGitCommit(git.CommitOptions{
+		Message:  commit.Text(),
+		StageAll: !t.staged && len(t.files) == 0,
+		Files:    t.files,
+		Cleanup:  t.cleanup,
+		Args:     t.gitArgs,
+	})
+}
+
+// describeCommitError explains why git commit failed, including the hook
+// or signing output so the user can act on it.
+func describeCommitError(err error) string {
+	var commitErr *git.CommitError
+	if !errors.As(err, &commitErr) {
+		return err.Error()
+	}
+
+	switch {
+	case commitErr.Signing:
+		return fmt.Sprintf("Signing the commit failed:\n\n%s\n\nCheck user.signingkey and gpg.format in your git config, or pass -- --no-gpg-sign.", commitErr.Output)
+	case commitErr.Hook != "":
+		return fmt.Sprintf("The %s hook rejected the commit:\n\n%s\n\nFix the issues and commit again, or pass -- --no-verify to skip hooks.", commitErr.Hook, commitErr.Output)
+	default:
+		return fmt.Sprintf("git commit failed:\n\n%s", commitErr.Output)
+	}
 }
@@ -0,0 +1,215 @@
+package tui
+
+import (
+	"commi/internal/core"
+	"commi/internal/git"
+	"commi/internal/history"
+	"commi/internal/utils"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+func openHistory() (*history.Store, error) {
+	root, err := git.GetRepoRoot()
+	if err != nil {
+		return nil, err
+	}
+	return history.Open(root)
+}
+
+// recordGeneration keeps a generated message in the repository's history.
+// The provider and model come from the core rather than the usage, which is
+// empty for cached messages. Failures are only logged, the history is a
+// convenience.
+func recordGeneration(commit *Commit, diffs string, c *core.Core) {
+	store, err := openHistory()
+	if err != nil {
+		log.Debug().Err(err).Msg("Failed to open history")
+		return
+	}
+
+	title, body, _ := strings.Cut(commit.Text(), "\n")
+	provider, model := c.Model()
+	id, err := store.Add(history.Entry{
+		DiffHash: history.HashDiff(diffs),
+		Provider: provider,
+		Model:    model,
+		Title:    title,
+		Body:     strings.TrimSpace(body),
+	})
+	if err != nil {
+		log.Debug().Err(err).Msg("Failed to record generation in history")
+		return
+	}
+	commit.historyID = id
+}
+
+// recordAction stores what the user did with a generated message.
+func recordAction(commit *Commit, action history.Action) {
+	if commit.historyID == "" {
+		return
+	}
+	store, err := openHistory()
+	if err == nil {
+		err = store.SetAction(commit.historyID, action)
+	}
+	if err != nil {
+		log.Debug().Err(err).Msg("Failed to record action in history")
+	}
+}
+
+// menuAction maps the choice made in the menu to a history action.
+func menuAction(m model) history.Action {
+	switch m.choice {
+	case CommitThis:
+		if m.committed {
+			return history.ActionCommitted
+		}
+	case CopyToClipboard:
+		return history.ActionCopied
+	case Regenerate:
+		return history.ActionRegenerated
+	}
+	return history.ActionCancelled
+}
+
+// ===== HISTORY BROWSER
+
+type historyItem struct {
+	entry history.Entry
+}
+
+func (i historyItem) Title() string { return i.entry.Title }
+func (i historyItem) Description() string {
+	desc := fmt.Sprintf("%s · %s", i.entry.Time.Local().Format("2006-01-02 15:04"), i.entry.Action)
+	if i.entry.Model != "" {
+		desc += " · " + i.entry.Model
+	}
+	return desc
+}
+func (i historyItem) FilterValue() string { return i.entry.Title + "\n" + i.entry.Body }
+
+type historyModel struct {
+	list     list.Model
+	selected *history.Entry
+}
+
+func (m historyModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m historyModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.list.SetSize(msg.Width, msg.Height)
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.list.FilterState() == list.Filtering {
+			break
+		}
+		switch msg.String() {
+		case "enter":
+			if i, ok := m.list.SelectedItem().(historyItem); ok {
+				m.selected = &i.entry
+			}
+			return m, tea.Quit
+		case "q", "ctrl+c":
+			return m, tea.Quit
+		}
+	}
+
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+	return m, cmd
+}
+
+func (m historyModel) View() string {
+	return m.list.View()
+}
+
+// RunHistory lists the messages generated in this repository, newest
+// first, and lets the user copy or commit one of them again.
+func RunHistory(cmd *cobra.Command, args []string) {
+	store, err := openHistory()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to open history")
+		os.Exit(1)
+	}
+	entries, err := store.List()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to read history")
+		os.Exit(1)
+	}
+	if len(entries) == 0 {
+		fmt.Println("No commit messages were generated in this repository yet.")
+		return
+	}
+
+	if !utils.IsTTY() {
+		for _, e := range entries {
+			fmt.Printf("%s  %-11s  %s\n", e.Time.Local().Format("2006-01-02 15:04"), e.Action, e.Title)
+		}
+		return
+	}
+
+	items := make([]list.Item, len(entries))
+	for i, e := range entries {
+		items[i] = historyItem{entry: e}
+	}
+	l := list.New(items, list.NewDefaultDelegate(), 0, 0)
+	l.Title = "Generated commit messages"
+	l.Styles.Title = titleStyle
+
+	p := tea.NewProgram(historyModel{list: l}, tea.WithAltScreen())
+	finalModel, err := p.Run()
+	if err != nil {
+		log.Error().Err(err).Msg("Error running Bubble Tea program")
+		os.Exit(1)
+	}
+	m, ok := finalModel.(historyModel)
+	if !ok || m.selected == nil {
+		return
+	}
+
+	reuseEntry(*m.selected)
+}
+
+// reuseEntry shows a message from the history with the option to commit
+// the current changes with it or copy it.
+func reuseEntry(entry history.Entry) {
+	commit := &Commit{Title: entry.Title, Message: entry.Body}
+	target := commitTarget{}
+
+	items := []list.Item{
+		item{title: "✅ Commit this", action: CommitThis},
+		item{title: "📋 Copy to clipboard and exit", action: CopyToClipboard},
+		item{title: "❌ Cancel", action: Cancel},
+	}
+	m, err := runMenu(items, commit, target)
+	if err != nil {
+		log.Error().Err(err).Msg("Error running Bubble Tea program")
+		os.Exit(1)
+	}
+	if m.quitting {
+		return
+	}
+	switch m.choice {
+	case CommitThis:
+		if m.committed {
+			log.Info().Msg("Commit successfully created!")
+		}
+	case CopyToClipboard:
+		if err := copyToClipboard(commit.Text()); err != nil {
+			log.Error().Err(err).Msg("Failed to copy to clipboard")
+		} else {
+			log.Info().Msg("Commit message copied to clipboard.")
+		}
+	}
+}
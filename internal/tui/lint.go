@@ -0,0 +1,89 @@
+package tui
+
+import (
+	"commi/internal/config"
+	"commi/internal/lint"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+func lintOptions(cfg *config.Config) lint.Options {
+	return lint.Options{
+		TitleLength:    cfg.LintTitleLength,
+		BodyWidth:      cfg.LintBodyWidth,
+		ForbiddenWords: cfg.LintForbiddenWords,
+		RequireTicket:  cfg.LintRequireTicket,
+		TicketPatterns: cfg.TicketPatterns,
+		Disabled:       cfg.LintDisable,
+	}
+}
+
+// lintCommit fixes what the linter can in a generated message and adds
+// what was fixed and the remaining problems to its warnings. Body width is
+// left to the formatter.
+func lintCommit(cfg *config.Config, commit *Commit) {
+	m := lint.Message{Title: commit.Title, Body: commit.Message}
+	for _, t := range commit.Trailers {
+		m.Trailers = append(m.Trailers, t.String())
+	}
+
+	// The body was already wrapped at COMMI_WRAP_WIDTH when it was formatted,
+	// re-wrapping it at the lint width would override that
+	opts := lintOptions(cfg)
+	opts.Disabled = append(opts.Disabled[:len(opts.Disabled):len(opts.Disabled)], "body-width")
+
+	fixed, issues := lint.Lint(m, opts, true)
+	commit.Title, commit.Message = fixed.Title, fixed.Body
+	for _, issue := range issues {
+		if issue.Fixed {
+			commit.Warnings = append(commit.Warnings, "🔧 "+issue.String())
+		} else {
+			commit.Warnings = append(commit.Warnings, "⚠️  "+issue.String())
+		}
+	}
+}
+
+// RunLint checks a commit message file, e.g. from a commit-msg hook, and
+// exits with status 1 when problems remain. With --fix the fixable ones are
+// corrected in place. "-" reads the message from stdin and prints the fixed
+// message to stdout.
+func RunLint(cmd *cobra.Command, args []string) {
+	path := args[0]
+	fix, _ := cmd.Flags().GetBool("fix")
+
+	var content []byte
+	var err error
+	if path == "-" {
+		content, err = io.ReadAll(os.Stdin)
+	} else {
+		content, err = os.ReadFile(path)
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to read commit message")
+		os.Exit(1)
+	}
+
+	message := lint.Parse(string(content))
+	fixed, issues := lint.Lint(message, lintOptions(config.Load()), fix)
+
+	switch {
+	case path == "-" && fix:
+		fmt.Print(fixed.String())
+	case fix && fixed.String() != message.String():
+		if err := os.WriteFile(path, []byte(fixed.String()), 0644); err != nil {
+			log.Error().Err(err).Msg("Failed to write the fixed commit message")
+			os.Exit(1)
+		}
+	}
+
+	for _, issue := range issues {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", path, issue)
+	}
+	if lint.Remaining(issues) > 0 {
+		os.Exit(1)
+	}
+}
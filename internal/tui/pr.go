@@ -0,0 +1,137 @@
+package tui
+
+import (
+	"commi/internal/config"
+	"commi/internal/core"
+	"commi/internal/git"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// prTemplatePaths are the locations GitHub looks for a pull request
+// template, relative to the repository root.
+var prTemplatePaths = []string{
+	".github/pull_request_template.md",
+	".github/PULL_REQUEST_TEMPLATE.md",
+	"PULL_REQUEST_TEMPLATE.md",
+	"pull_request_template.md",
+	"docs/pull_request_template.md",
+	"docs/PULL_REQUEST_TEMPLATE.md",
+}
+
+func readPRTemplate() string {
+	root, err := git.GetRepoRoot()
+	if err != nil {
+		return ""
+	}
+	for _, p := range prTemplatePaths {
+		content, err := os.ReadFile(filepath.Join(root, p))
+		if err == nil {
+			log.Debug().Msgf("Using pull request template %s", p)
+			return string(content)
+		}
+	}
+	return ""
+}
+
+// ===== PULL REQUEST GENERATION
+
+func RunPR(cmd *cobra.Command, args []string, c *core.Core) {
+	base, _ := cmd.Flags().GetString("base")
+	jsonFlag, _ := cmd.Flags().GetBool("json")
+	copyFlag, _ := cmd.Flags().GetBool("copy")
+
+	if base == "" {
+		base = config.Load().BaseBranch
+	}
+	if base == "" {
+		var err error
+		if base, err = git.GetDefaultBranch(); err != nil {
+			log.Error().Err(err).Msg("Failed to detect base branch, use --base to set it")
+			os.Exit(1)
+		}
+	}
+
+	branch, err := git.GetCurrentBranch()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get current branch")
+		os.Exit(1)
+	}
+
+	mergeBase, err := git.GetMergeBase(base, "HEAD")
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to find merge base")
+		os.Exit(1)
+	}
+
+	entries, err := git.GetLog(mergeBase + "..HEAD")
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get commit log")
+		os.Exit(1)
+	}
+	if len(entries) == 0 {
+		fmt.Fprintf(os.Stderr, "No commits between %s and %s.\n", base, branch)
+		return
+	}
+
+	stat, err := git.GetRangeStat(mergeBase, "HEAD")
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get diff stat")
+		os.Exit(1)
+	}
+	diffs, err := git.GetRangeDiff(mergeBase, "HEAD")
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get diff")
+		os.Exit(1)
+	}
+
+	var subject string
+	if len(args) > 0 {
+		subject = args[0]
+	}
+
+	opts := core.PROptions{
+		Branch:   branch,
+		Base:     base,
+		Log:      git.FormatLog(entries),
+		Stat:     stat,
+		Diffs:    redactOrExit(diffs),
+		Template: readPRTemplate(),
+		Subject:  subject,
+	}
+
+	spinner := NewSpinner()
+	spinner.Start("Generating pull request description...")
+	pr, err := c.GeneratePR(context.Background(), opts)
+	spinner.Stop()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to generate pull request description")
+		os.Exit(1)
+	}
+
+	content := fmt.Sprintf("%s\n\n%s", pr.Title, pr.Body)
+	if jsonFlag {
+		out, err := json.MarshalIndent(pr, "", "  ")
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to encode pull request")
+			os.Exit(1)
+		}
+		content = string(out)
+	}
+
+	fmt.Println(content)
+
+	if copyFlag {
+		if err := copyToClipboard(content); err != nil {
+			log.Error().Err(err).Msg("Failed to copy to clipboard")
+			os.Exit(1)
+		}
+		log.Info().Msg("Pull request description copied to clipboard.")
+	}
+}
@@ -0,0 +1,73 @@
+package tui
+
+import (
+	"commi/internal/config"
+	"commi/internal/core"
+	"commi/internal/git"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// recentCommits is the number of commit titles the commit prompt shows as
+// examples of the repository's conventions.
+const recentCommits = 10
+
+// promptData collects the template variables of the commit prompt.
+func promptData(cfg *config.Config, files []string, subject string, tickets []string) core.PromptData {
+	branch, _ := git.GetCurrentBranch()
+	data := core.PromptData{
+		Branch:        branch,
+		Subject:       subject,
+		Language:      cfg.Language,
+		Style:         cfg.Style,
+		RecentCommits: git.GetRecentSubjects(recentCommits),
+		Files:         files,
+		Format:        cfg.OutputFormat,
+	}
+	if cfg.HasTicketMode(config.TicketModePrompt) {
+		data.Tickets = tickets
+	}
+	if data.Format == "" {
+		data.Format = core.OutputXML
+	}
+	return data
+}
+
+// RunPromptShow prints a prompt rendered with the current repository's
+// data, and where its template was read from.
+func RunPromptShow(cmd *cobra.Command, args []string) {
+	name := core.PromptCommit
+	if len(args) > 0 {
+		name = args[0]
+	}
+	subject, _ := cmd.Flags().GetString("subject")
+
+	cfg := config.Load()
+	// A clean tree renders without files
+	var files []string
+	status, err := git.GetGitStatus()
+	switch {
+	case err == nil:
+		files, _ = git.GetChangedFiles(status)
+	case !errors.Is(err, git.ErrNothingToCommit):
+		log.Error().Err(err).Msg("Failed to get git status")
+		os.Exit(1)
+	}
+
+	prompt, err := core.RenderPrompt(name, promptData(cfg, files, subject, branchTickets(cfg)))
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to render prompt")
+		os.Exit(1)
+	}
+	source := core.PromptSource(name)
+	fmt.Fprintf(os.Stderr, "# %s prompt from %s\n", name, source)
+	if source == "built-in" {
+		fmt.Fprintln(os.Stderr, "# Overrides can also use {{.Branch}}, {{.Subject}} and {{.Files}}, the built-in prompt leaves them to the request")
+	}
+	fmt.Fprintln(os.Stderr)
+	fmt.Println(prompt)
+}
@@ -0,0 +1,65 @@
+package tui
+
+import (
+	"commi/internal/config"
+	"commi/internal/core"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// redactDiffs masks secrets in the diffs according to COMMI_REDACT. In block
+// mode nothing is sent when a secret is found.
+func redactDiffs(cfg *config.Config, diffs string) (string, []core.Secret, error) {
+	switch cfg.Redact {
+	case config.RedactOff:
+		return diffs, nil, nil
+	case config.RedactMask, config.RedactBlock:
+	default:
+		log.Warn().Msgf("Unknown COMMI_REDACT mode %q, masking secrets", cfg.Redact)
+	}
+
+	masked, secrets := core.RedactSecrets(diffs)
+	if len(secrets) == 0 {
+		return diffs, nil, nil
+	}
+	if cfg.Redact == config.RedactBlock {
+		return "", secrets, fmt.Errorf("refusing to send diffs with possible secrets (%s), remove them or set COMMI_REDACT=mask", describeSecrets(secrets))
+	}
+	log.Debug().Msgf("Masked %d possible secrets", len(secrets))
+	return masked, secrets, nil
+}
+
+// redactOrExit is redactDiffs for commands without a review menu: secrets
+// are reported as a warning and block mode exits.
+func redactOrExit(diffs string) string {
+	masked, secrets, err := redactDiffs(config.Load(), diffs)
+	if err != nil {
+		log.Error().Err(err).Msg("Not sending diffs")
+		os.Exit(1)
+	}
+	if len(secrets) > 0 {
+		log.Warn().Msg(secretWarning(secrets))
+	}
+	return masked
+}
+
+// secretWarning tells the user what was masked before sending the diffs.
+func secretWarning(secrets []core.Secret) string {
+	return fmt.Sprintf("⚠️  Masked %d possible secrets before sending the diff: %s. Make sure they aren't committed.", len(secrets), describeSecrets(secrets))
+}
+
+// describeSecrets lists each kind of secret and file once.
+func describeSecrets(secrets []core.Secret) string {
+	seen := make(map[string]bool)
+	var parts []string
+	for _, s := range secrets {
+		if desc := s.String(); !seen[desc] {
+			seen[desc] = true
+			parts = append(parts, desc)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
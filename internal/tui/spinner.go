@@ -3,6 +3,7 @@ package tui
 import (
 	"commi/internal/utils"
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/charmbracelet/bubbles/spinner"
@@ -26,6 +27,7 @@ type spinnerModel struct {
 	state    string
 	duration time.Duration
 	text     string
+	info     string
 }
 
 func NewSpinner() *Spinner {
@@ -51,9 +53,10 @@ func (s *Spinner) Start(message string) {
 	s.model.text = message
 	s.startTime = time.Now()
 
-	// If not in TTY, just print the message
+	// If not in TTY, just print the message, to stderr so that redirected
+	// output only holds the result
 	if !s.isTTY {
-		fmt.Printf("⏺ %s\n", message)
+		fmt.Fprintf(os.Stderr, "⏺ %s\n", message)
 		return
 	}
 
@@ -67,16 +70,22 @@ func (s *Spinner) Start(message string) {
 }
 
 func (s *Spinner) Stop() {
+	s.StopWithInfo("")
+}
+
+// StopWithInfo stops the spinner and adds info, such as the tokens used,
+// to the "Done!" line.
+func (s *Spinner) StopWithInfo(info string) {
 	if !s.isTTY {
 		return
 	}
-	s.program.Send(doneMsg{duration: time.Since(s.startTime)})
+	s.program.Send(doneMsg{duration: time.Since(s.startTime), info: info})
 	<-s.doneChan
 }
 
 func (s *Spinner) UpdateText(text string) {
 	if !s.isTTY {
-		fmt.Printf("⏺ %s\n", text)
+		fmt.Fprintf(os.Stderr, "⏺ %s\n", text)
 		return
 	}
 	s.program.Send(updateTextMsg(text))
@@ -84,6 +93,7 @@ func (s *Spinner) UpdateText(text string) {
 
 type doneMsg struct {
 	duration time.Duration
+	info     string
 }
 
 type updateTextMsg string
@@ -106,6 +116,7 @@ func (m spinnerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case doneMsg:
 		m.state = "done"
 		m.duration = msg.duration
+		m.info = msg.info
 		return m, tea.Quit
 	case updateTextMsg:
 		m.text = string(msg)
@@ -125,6 +136,9 @@ func (m spinnerModel) View() string {
 	case "quitting":
 		return "\n"
 	case "done":
+		if m.info != "" {
+			return fmt.Sprintf("\n\n   Done! Took %.2f seconds, %s\n\n", m.duration.Seconds(), m.info)
+		}
 		return fmt.Sprintf("\n\n   Done! Took %.2f seconds\n\n", m.duration.Seconds())
 	default:
 		return fmt.Sprintf("\n\n   %s %s\n\n", m.spinner.View(), m.text)
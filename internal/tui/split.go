@@ -0,0 +1,202 @@
+package tui
+
+import (
+	"commi/internal/core"
+	"commi/internal/git"
+	"commi/internal/history"
+	"commi/internal/utils"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var splitFileStyle = lipgloss.NewStyle().PaddingLeft(8).Faint(true)
+
+type splitModel struct {
+	groups    []core.CommitGroup
+	cursor    int
+	confirmed bool
+	quitting  bool
+}
+
+func (m splitModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m splitModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch keyMsg.String() {
+	case "q", "esc", "ctrl+c":
+		m.quitting = true
+		return m, tea.Quit
+	case "enter":
+		m.confirmed = true
+		return m, tea.Quit
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < len(m.groups)-1 {
+			m.cursor++
+		}
+	case "shift+up", "K":
+		if m.cursor > 0 {
+			m.groups[m.cursor], m.groups[m.cursor-1] = m.groups[m.cursor-1], m.groups[m.cursor]
+			m.cursor--
+		}
+	case "shift+down", "J":
+		if m.cursor < len(m.groups)-1 {
+			m.groups[m.cursor], m.groups[m.cursor+1] = m.groups[m.cursor+1], m.groups[m.cursor]
+			m.cursor++
+		}
+	case "m":
+		// Merge the selected group with the next one
+		if m.cursor < len(m.groups)-1 {
+			next := m.groups[m.cursor+1]
+			m.groups[m.cursor].Files = append(m.groups[m.cursor].Files, next.Files...)
+			m.groups = append(m.groups[:m.cursor+1], m.groups[m.cursor+2:]...)
+		}
+	}
+	return m, nil
+}
+
+func (m splitModel) View() string {
+	if m.quitting {
+		return quitTextStyle.Render("Exiting...")
+	}
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("Proposed commits"))
+	b.WriteString("\n\n")
+	for i, g := range m.groups {
+		line := fmt.Sprintf("%d. %s", i+1, g.Name)
+		if i == m.cursor {
+			b.WriteString(selectedItemStyle.Render("> " + line))
+		} else {
+			b.WriteString(itemStyle.Render(line))
+		}
+		b.WriteString("\n")
+		for _, f := range g.Files {
+			b.WriteString(splitFileStyle.Render(f))
+			b.WriteString("\n")
+		}
+	}
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("↑/↓ select • K/J move • m merge with next • enter commit all • q cancel"))
+	return b.String()
+}
+
+func renderSplitPlan(groups []core.CommitGroup) string {
+	var b strings.Builder
+	for i, g := range groups {
+		fmt.Fprintf(&b, "%d. %s\n", i+1, g.Name)
+		for _, f := range g.Files {
+			fmt.Fprintf(&b, "   %s\n", f)
+		}
+	}
+	return b.String()
+}
+
+// ===== SPLIT COMMITS
+
+func RunSplit(cmd *cobra.Command, args []string, c *core.Core) {
+	status, err := git.GetGitStatus()
+	if err != nil {
+		if errors.Is(err, git.ErrNothingToCommit) {
+			fmt.Println("No changes to commit. Make some changes and try again.")
+			return
+		}
+		log.Error().Err(err).Msg("Failed to get git status")
+		os.Exit(1)
+	}
+
+	files, err := git.GetChangedFiles(status)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get changed files")
+		os.Exit(1)
+	}
+
+	commitOpts, err := commitOptionsFromFlags(cmd, args)
+	if err != nil {
+		log.Error().Err(err).Msg("Invalid commit options")
+		os.Exit(1)
+	}
+
+	forceFlag, _ := cmd.Flags().GetBool("force")
+
+	spinner := NewSpinner()
+	spinner.Start("Planning commits...")
+	groups, err := c.PlanSplit(context.Background(), core.SplitOptions{
+		Status: status,
+		Diffs:  redactOrExit(git.GetGitDiffs(files)),
+		Files:  files,
+	})
+	spinner.Stop()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to plan commits")
+		os.Exit(1)
+	}
+
+	if !forceFlag {
+		if !utils.IsTTY() {
+			fmt.Printf("Proposed commits:\n%s", renderSplitPlan(groups))
+			fmt.Println("\nRun with -f flag to apply these commits automatically in non-interactive environments.")
+			return
+		}
+
+		p := tea.NewProgram(splitModel{groups: groups}, tea.WithAltScreen())
+		finalModel, err := p.Run()
+		if err != nil {
+			log.Error().Err(err).Msg("Error running Bubble Tea program")
+			os.Exit(1)
+		}
+		m, ok := finalModel.(splitModel)
+		if !ok || !m.confirmed {
+			log.Info().Msg("Split aborted.")
+			return
+		}
+		groups = m.groups
+	}
+
+	for i, g := range groups {
+		groupStatus := git.FilterStatus(status, g.Files)
+		groupDiffs := git.GetGitDiffs(g.Files)
+		if strings.TrimSpace(groupDiffs) == "" {
+			// Untracked files have no diff yet, describe them by their status
+			groupDiffs = groupStatus
+		}
+
+		commit, err := generateCommitMessage(c, groupStatus, groupDiffs, commitOpts, false)
+		if err != nil {
+			log.Error().Err(err).Msgf("Failed to generate commit message for group %d", i+1)
+			os.Exit(1)
+		}
+
+		printWarnings(commit)
+
+		err = git.ExecuteGitCommit(git.CommitOptions{
+			Message: commit.Text(),
+			Files:   g.Files,
+			Cleanup: commitOpts.cleanup,
+			Args:    commitOpts.gitArgs,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to commit group %d. %s\n", i+1, describeCommitError(err))
+			os.Exit(1)
+		}
+		recordAction(commit, history.ActionCommitted)
+		fmt.Printf("Commit %d/%d applied: %s\n", i+1, len(groups), commit.Title)
+	}
+}
@@ -0,0 +1,256 @@
+package tui
+
+import (
+	"commi/internal/core"
+	"commi/internal/git"
+	"commi/internal/utils"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+const hunkPreviewLines = 12
+
+var (
+	fileHeaderStyle  = lipgloss.NewStyle().PaddingLeft(2).Bold(true)
+	addedLineStyle   = lipgloss.NewStyle().PaddingLeft(4).Foreground(lipgloss.Color("2"))
+	removedLineStyle = lipgloss.NewStyle().PaddingLeft(4).Foreground(lipgloss.Color("1"))
+	contextLineStyle = lipgloss.NewStyle().PaddingLeft(4).Faint(true)
+)
+
+// hunkRef points to a hunk inside the parsed diff.
+type hunkRef struct {
+	file int
+	hunk int
+}
+
+type stageModel struct {
+	files     []git.FileDiff
+	hunks     []hunkRef
+	selected  map[hunkRef]bool
+	cursor    int
+	confirmed bool
+	quitting  bool
+}
+
+func newStageModel(files []git.FileDiff) stageModel {
+	m := stageModel{files: files, selected: make(map[hunkRef]bool)}
+	for fi, f := range files {
+		for hi := range f.Hunks {
+			m.hunks = append(m.hunks, hunkRef{file: fi, hunk: hi})
+		}
+	}
+	return m
+}
+
+func (m stageModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m stageModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch keyMsg.String() {
+	case "q", "esc", "ctrl+c":
+		m.quitting = true
+		return m, tea.Quit
+	case "enter":
+		m.confirmed = true
+		return m, tea.Quit
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < len(m.hunks)-1 {
+			m.cursor++
+		}
+	case " ", "x":
+		ref := m.hunks[m.cursor]
+		m.selected[ref] = !m.selected[ref]
+	case "a":
+		// Toggle every hunk of the current file
+		file := m.hunks[m.cursor].file
+		all := true
+		for _, ref := range m.hunks {
+			if ref.file == file && !m.selected[ref] {
+				all = false
+				break
+			}
+		}
+		for _, ref := range m.hunks {
+			if ref.file == file {
+				m.selected[ref] = !all
+			}
+		}
+	}
+	return m, nil
+}
+
+func (m stageModel) View() string {
+	if m.quitting {
+		return quitTextStyle.Render("Exiting...")
+	}
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("Select hunks to commit"))
+	b.WriteString("\n\n")
+
+	lastFile := -1
+	for i, ref := range m.hunks {
+		if ref.file != lastFile {
+			b.WriteString(fileHeaderStyle.Render(m.files[ref.file].Path))
+			b.WriteString("\n")
+			lastFile = ref.file
+		}
+		check := "[ ]"
+		if m.selected[ref] {
+			check = "[x]"
+		}
+		line := fmt.Sprintf("%s %s", check, m.files[ref.file].Hunks[ref.hunk].Header)
+		if i == m.cursor {
+			b.WriteString(selectedItemStyle.Render("> " + line))
+		} else {
+			b.WriteString(itemStyle.Render(line))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(renderHunkPreview(m.files[m.hunks[m.cursor].file].Hunks[m.hunks[m.cursor].hunk]))
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("↑/↓ select • space toggle hunk • a toggle file • enter stage and generate • q cancel"))
+	return b.String()
+}
+
+func renderHunkPreview(h git.Hunk) string {
+	var b strings.Builder
+	for i, line := range h.Lines {
+		if i == hunkPreviewLines {
+			b.WriteString(contextLineStyle.Render(fmt.Sprintf("... %d more lines", len(h.Lines)-hunkPreviewLines)))
+			b.WriteString("\n")
+			break
+		}
+		switch {
+		case strings.HasPrefix(line, "+"):
+			b.WriteString(addedLineStyle.Render(line))
+		case strings.HasPrefix(line, "-"):
+			b.WriteString(removedLineStyle.Render(line))
+		default:
+			b.WriteString(contextLineStyle.Render(line))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// patch builds a patch containing only the selected hunks.
+func (m stageModel) patch() string {
+	var b strings.Builder
+	for fi, f := range m.files {
+		var hunks []git.Hunk
+		for hi, h := range f.Hunks {
+			if m.selected[hunkRef{file: fi, hunk: hi}] {
+				hunks = append(hunks, h)
+			}
+		}
+		if len(hunks) > 0 {
+			b.WriteString(f.Patch(hunks))
+		}
+	}
+	return b.String()
+}
+
+// ===== INTERACTIVE STAGING
+
+func RunStage(cmd *cobra.Command, args []string, c *core.Core) {
+	if !utils.IsTTY() {
+		fmt.Println("Interactive staging requires a terminal.")
+		return
+	}
+
+	// The commit takes the whole index, so it has to hold only the selection
+	staged, err := git.GetStagedStatus()
+	switch {
+	case err == nil:
+		fmt.Printf("Some changes are already staged:\n\n%s\nCommit them or unstage them with \"git restore --staged .\" before selecting hunks.\n", staged)
+		return
+	case !errors.Is(err, git.ErrNothingToCommit):
+		log.Error().Err(err).Msg("Failed to get staged changes")
+		os.Exit(1)
+	}
+
+	diff, err := git.GetWorkingDiff()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get git diff")
+		os.Exit(1)
+	}
+
+	m := newStageModel(git.ParseDiff(diff))
+	if len(m.hunks) == 0 {
+		fmt.Println("No unstaged changes to select. Make some changes and try again.")
+		return
+	}
+
+	p := tea.NewProgram(m, tea.WithAltScreen())
+	finalModel, err := p.Run()
+	if err != nil {
+		log.Error().Err(err).Msg("Error running Bubble Tea program")
+		os.Exit(1)
+	}
+	m, ok := finalModel.(stageModel)
+	if !ok || !m.confirmed {
+		log.Info().Msg("Staging aborted.")
+		return
+	}
+
+	if patch := m.patch(); patch != "" {
+		if err := git.ApplyCached(patch); err != nil {
+			log.Error().Err(err).Msg("Failed to stage selected hunks")
+			os.Exit(1)
+		}
+	}
+
+	RunStaged(cmd, args, c)
+}
+
+// RunStaged generates a commit message for the changes already staged in
+// the index and commits them without staging anything else.
+func RunStaged(cmd *cobra.Command, args []string, c *core.Core) {
+	status, err := git.GetStagedStatus()
+	if err != nil {
+		if errors.Is(err, git.ErrNothingToCommit) {
+			fmt.Println("No staged changes to commit.")
+			return
+		}
+		log.Error().Err(err).Msg("Failed to get staged changes")
+		os.Exit(1)
+	}
+
+	diffs, err := git.GetStagedDiff()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get staged diff")
+		os.Exit(1)
+	}
+
+	files, err := git.GetChangedFiles(status)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get staged files")
+		os.Exit(1)
+	}
+	changes, err := git.ClassifyChanges(files, true)
+	if err != nil {
+		log.Debug().Err(err).Msg("Failed to classify staged changes")
+	}
+
+	generate(cmd, args, c, status, git.FilterDiff(diffs, changes), true)
+}
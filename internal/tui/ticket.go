@@ -0,0 +1,46 @@
+package tui
+
+import (
+	"commi/internal/config"
+	"commi/internal/core"
+	"commi/internal/git"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// branchTickets returns the ticket IDs found in the current branch name.
+func branchTickets(cfg *config.Config) []string {
+	branch, err := git.GetCurrentBranch()
+	if err != nil {
+		log.Debug().Err(err).Msg("Failed to get current branch, skipping ticket detection")
+		return nil
+	}
+
+	tickets, err := core.ExtractTickets(branch, cfg.TicketPatterns)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to extract tickets from branch name")
+		return nil
+	}
+	if len(tickets) > 0 {
+		log.Debug().Msgf("Found tickets %v in branch %s", tickets, branch)
+	}
+	return tickets
+}
+
+// applyTickets adds the tickets to the commit as a title prefix and/or a
+// Refs trailer, depending on the configured ticket modes. An explicit
+// --prefix takes precedence over the ticket prefix.
+func applyTickets(cfg *config.Config, commit *Commit, tickets []string, prefix string) {
+	if len(tickets) == 0 {
+		return
+	}
+
+	if prefix == "" && cfg.HasTicketMode(config.TicketModePrefix) && !strings.Contains(commit.Title, tickets[0]) {
+		commit.Title = strings.Join(tickets, " ") + " " + commit.Title
+	}
+
+	if cfg.HasTicketMode(config.TicketModeTrailer) {
+		commit.Trailers = append(commit.Trailers, core.Trailer{Key: "Refs", Value: strings.Join(tickets, ", ")})
+	}
+}
@@ -1,14 +1,16 @@
 package tui
 
 import (
+	"commi/internal/config"
 	"commi/internal/core"
 	"commi/internal/git"
+	"commi/internal/history"
 	"commi/internal/utils"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
-	"os/exec"
 	"strings"
 
 	"github.com/atotto/clipboard"
@@ -28,6 +30,8 @@ var (
 	paginationStyle   = list.DefaultStyles().PaginationStyle.PaddingLeft(4)
 	helpStyle         = list.DefaultStyles().HelpStyle.PaddingLeft(4).PaddingBottom(1)
 	quitTextStyle     = lipgloss.NewStyle().Margin(1, 0, 2, 4)
+	errorStyle        = lipgloss.NewStyle().Margin(0, 0, 1, 2).Padding(0, 1).Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("1"))
+	warningStyle      = lipgloss.NewStyle().Margin(0, 0, 1, 2).Foreground(lipgloss.Color("3"))
 )
 
 type MenuAction int
@@ -52,12 +56,7 @@ func (d itemDelegate) Height() int                             { return 1 }
 func (d itemDelegate) Spacing() int                            { return 0 }
 func (d itemDelegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd { return nil }
 func (d itemDelegate) Render(w io.Writer, m list.Model, index int, listItem list.Item) {
-	i, ok := listItem.(item)
-	if !ok {
-		return
-	}
-
-	str := i.title
+	str := listItem.FilterValue()
 
 	fn := itemStyle.Render
 	if index == m.Index() {
@@ -74,6 +73,12 @@ type model struct {
 	commit   *Commit
 	choice   MenuAction
 	quitting bool
+
+	// committed is set by runMenu once the chosen commit was made.
+	committed bool
+	// commitErr is the failure of the previous commit attempt, shown above
+	// the menu.
+	commitErr error
 }
 
 func (m model) Init() tea.Cmd {
@@ -112,24 +117,38 @@ func (m model) View() string {
 	}
 
 	commitMessage := renderCommitMessage(m.commit)
+	for _, w := range m.commit.Warnings {
+		commitMessage += "\n\n" + warningStyle.Render(w)
+	}
+	if m.commitErr != nil {
+		return fmt.Sprintf("%s\n\n%s\n%s", commitMessage, errorStyle.Render(describeCommitError(m.commitErr)), m.list.View())
+	}
 	return fmt.Sprintf("%s\n\n%s", commitMessage, m.list.View())
 }
 
 func renderCommitMessage(commit *Commit) string {
-	return fmt.Sprintf("%s\n\n%s", commit.Title, commit.Message)
+	message := fmt.Sprintf("%s\n\n%s", commit.Title, commit.Message)
+	if len(commit.Trailers) > 0 {
+		message += "\n"
+		for _, t := range commit.Trailers {
+			message += "\n" + t.String()
+		}
+	}
+	return message
 }
 
-func handleUserResponse(cmd *cobra.Command, args []string, commit *Commit, c *core.Core) {
+func handleUserResponse(cmd *cobra.Command, args []string, commit *Commit, c *core.Core, target commitTarget) {
 	// Check if we're in a TTY environment
 	if !utils.IsTTY() {
+		printWarnings(commit)
 		// In non-TTY environment with force flag, apply commit directly
 		forceFlag, _ := cmd.Flags().GetBool("force")
 		if forceFlag {
-			handleForcedCommit(commit)
+			handleForcedCommit(commit, target)
 			return
 		}
 		// Otherwise, just print the commit message and exit
-		fmt.Printf("Generated commit message:\n%s\n\n%s\n", commit.Title, commit.Message)
+		fmt.Printf("Generated commit message:\n%s", commit.Text())
 		fmt.Println("\nRun with -f flag to apply this commit automatically in non-interactive environments.")
 		return
 	}
@@ -141,55 +160,80 @@ func handleUserResponse(cmd *cobra.Command, args []string, commit *Commit, c *co
 		item{title: "❌ Cancel", action: Cancel},
 	}
 
-	const defaultWidth = 30
-
-	l := list.New(items, itemDelegate{}, defaultWidth, listHeight)
-	l.Title = ""
-	l.SetShowStatusBar(false)
-	l.SetFilteringEnabled(false)
-	l.Styles.Title = titleStyle
-	l.Styles.PaginationStyle = paginationStyle
-	l.Styles.HelpStyle = helpStyle
-
-	m := model{list: l, commit: commit}
-
-	p := tea.NewProgram(m, tea.WithAltScreen())
-
-	finalModel, err := p.Run()
+	finalModel, err := runMenu(items, commit, target)
 	if err != nil {
 		log.Error().Err(err).Msg("Error running Bubble Tea program")
 		os.Exit(1)
 	}
 
-	if finalModel, ok := finalModel.(model); ok {
-		switch finalModel.choice {
-		case CommitThis:
-			if err := git.ExecuteGitAdd(); err != nil {
-				log.Error().Err(err).Msg("Failed to execute git add")
-				return
-			}
-			if err := git.ExecuteGitCommit(commit.Title, commit.Message); err != nil {
-				log.Error().Err(err).Msg("Failed to execute git commit")
-				return
-			}
+	if finalModel.quitting {
+		recordAction(commit, history.ActionCancelled)
+		return
+	}
+	recordAction(commit, menuAction(finalModel))
+	switch finalModel.choice {
+	case CommitThis:
+		if finalModel.committed {
 			log.Info().Msg("Commit successfully created!")
-		case CopyToClipboard:
-			content := fmt.Sprintf("%s\n\n%s", commit.Title, commit.Message)
-			log.Debug().Msg(fmt.Sprintf("Attempting to copy to clipboard: %s", content))
-			if err := copyToClipboard(content); err != nil {
-				log.Error().Err(err).Msg("Failed to copy to clipboard")
-			} else {
-				log.Info().Msg("Commit message copied to clipboard.")
-			}
-			log.Debug().Msg("Clipboard operation completed")
-		case Regenerate:
+		}
+	case CopyToClipboard:
+		content := commit.Text()
+		log.Debug().Msg(fmt.Sprintf("Attempting to copy to clipboard: %s", content))
+		if err := copyToClipboard(content); err != nil {
+			log.Error().Err(err).Msg("Failed to copy to clipboard")
+		} else {
+			log.Info().Msg("Commit message copied to clipboard.")
+		}
+		log.Debug().Msg("Clipboard operation completed")
+	case Regenerate:
+		// The same changes would hit the cache again
+		_ = cmd.Flags().Set("no-cache", "true")
+		if target.staged {
+			RunStaged(cmd, args, c)
+		} else {
 			Run(cmd, args, c)
-		case Cancel:
-			log.Info().Msg("Commit aborted.")
 		}
+	case Cancel:
+		log.Info().Msg("Commit aborted.")
 	}
 }
 
+// runMenu shows the menu for a commit until the user picks an action.
+// Committing happens after the menu has closed, so GPG pinentry and
+// interactive hooks get the terminal; when it fails the menu is shown again
+// with the error.
+func runMenu(items []list.Item, commit *Commit, target commitTarget) (model, error) {
+	var commitErr error
+	for {
+		p := tea.NewProgram(model{list: newMenu(items), commit: commit, commitErr: commitErr}, tea.WithAltScreen())
+		finalModel, err := p.Run()
+		if err != nil {
+			return model{}, err
+		}
+		m, ok := finalModel.(model)
+		if !ok || m.quitting || m.choice != CommitThis {
+			return m, nil
+		}
+		if commitErr = target.apply(commit); commitErr == nil {
+			m.committed = true
+			return m, nil
+		}
+	}
+}
+
+func newMenu(items []list.Item) list.Model {
+	const defaultWidth = 30
+
+	l := list.New(items, itemDelegate{}, defaultWidth, listHeight)
+	l.Title = ""
+	l.SetShowStatusBar(false)
+	l.SetFilteringEnabled(false)
+	l.Styles.Title = titleStyle
+	l.Styles.PaginationStyle = paginationStyle
+	l.Styles.HelpStyle = helpStyle
+	return l
+}
+
 func copyToClipboard(content string) error {
 	log.Debug().Msg("Entering copyToClipboard function")
 	err := clipboard.WriteAll(content)
@@ -201,20 +245,40 @@ func copyToClipboard(content string) error {
 	return nil
 }
 
-func generateCommitMessage(c *core.Core, status, diffs, subject string) (*Commit, error) {
+func generateCommitMessage(c *core.Core, status, diffs string, commitOpts commitOptions, staged bool) (*Commit, error) {
+	cfg := config.Load()
+	diffs, secrets, err := redactDiffs(cfg, diffs)
+	if err != nil {
+		return nil, err
+	}
+
 	spinner := NewSpinner()
 	spinner.Start("Generating commit message...")
 
-	sys := core.SystemPrompt
-	if _, exists := os.LookupEnv("DISABLE_EMOJI"); !exists {
-		sys += "\n• Please follow the gitmoji standard (https://gitmoji.dev/) and feel free to use emojis in the commit messages where appropriate to enhance readability and convey the nature of the changes."
-	}
+	tickets := branchTickets(cfg)
 
+	_, disableEmoji := os.LookupEnv("DISABLE_EMOJI")
+
+	files, changes := classifyStatus(status, staged)
+	sys, err := core.RenderPrompt(core.PromptCommit, promptData(cfg, files, commitOpts.subject, tickets))
+	if err != nil {
+		spinner.Stop()
+		return nil, err
+	}
 	opts := core.GenerateOptions{
 		SystemPrompt: sys,
 		Status:       status,
 		Diffs:        diffs,
-		Subject:      subject,
+		Subject:      commitOpts.subject,
+		Files:        summarizedFiles(files, changes),
+		Format: core.FormatOptions{
+			Width:      cfg.WrapWidth,
+			StripEmoji: disableEmoji,
+		},
+		NoCache: commitOpts.noCache,
+	}
+	if cfg.SemanticDiff {
+		opts.APIChanges = apiChanges(files, changes, staged)
 	}
 
 	if utils.IsDebug() {
@@ -223,11 +287,19 @@ func generateCommitMessage(c *core.Core, status, diffs, subject string) (*Commit
 		log.Debug().Msgf("Status: %d bytes", len(status))
 		log.Debug().Msgf("Status: %s", status)
 		log.Debug().Msgf("Diffs: %d bytes", len(diffs))
-		log.Debug().Msgf("Subject: %s", subject)
+		log.Debug().Msgf("API changes: %s", opts.APIChanges)
+		log.Debug().Msgf("Subject: %s", commitOpts.subject)
 	}
 
-	commit, err := c.GenerateCommit(context.Background(), opts)
-	spinner.Stop()
+	generated, err := c.GenerateCommit(context.Background(), opts)
+	switch {
+	case err != nil:
+		spinner.Stop()
+	case generated.Cached:
+		spinner.StopWithInfo("from cache, run with --no-cache for a new message")
+	default:
+		spinner.StopWithInfo(generated.Usage.String())
+	}
 
 	if err != nil {
 		if utils.IsDebug() {
@@ -236,31 +308,67 @@ func generateCommitMessage(c *core.Core, status, diffs, subject string) (*Commit
 		return nil, err
 	}
 
+	commit := generated.Commit
 	if utils.IsDebug() {
-		log.Debug().Interface("commit", commit).Msg("Generated commit message")
+		log.Debug().Interface("commit", commit).Interface("usage", generated.Usage).Msg("Generated commit message")
+	}
+
+	result := &Commit{
+		Title:    commit.Title,
+		Message:  commit.Message,
+		Trailers: commit.Trailers,
+	}
+	if len(secrets) > 0 {
+		result.Warnings = append(result.Warnings, secretWarning(secrets))
+	}
+	applyTickets(cfg, result, tickets, commitOpts.prefix)
+	applyTrailers(cfg, result, commitOpts)
+	if commitOpts.prefix != "" {
+		result.Title = commitOpts.prefix + " " + result.Title
 	}
+	lintCommit(cfg, result)
+	recordGeneration(result, diffs, c)
+
+	return result, nil
+}
 
-	return &Commit{
-		Title:   commit.Title,
-		Message: commit.Message,
-	}, nil
+// classifyStatus classifies the files of a status for the prompt.
+func classifyStatus(status string, staged bool) ([]string, map[string]git.FileChange) {
+	files, err := git.GetChangedFiles(status)
+	if err != nil {
+		return nil, nil
+	}
+	changes, err := git.ClassifyChanges(files, staged)
+	if err != nil {
+		log.Debug().Err(err).Msg("Failed to classify changes")
+	}
+	return files, changes
 }
 
-func applyCommit(c *Commit) error {
-	// Stage all changes
-	stageCmd := exec.Command("git", "add", "-A")
-	stageOutput, stageErr := stageCmd.CombinedOutput()
-	if stageErr != nil {
-		return fmt.Errorf("failed to stage changes: %v\nOutput: %s", stageErr, string(stageOutput))
+// summarizedFiles returns the changed files that are summarized rather than
+// diffed, for the prompt.
+func summarizedFiles(files []string, changes map[string]git.FileChange) []core.FileInfo {
+	var infos []core.FileInfo
+	for _, f := range files {
+		if c, ok := changes[f]; ok && c.Kind != git.KindText {
+			infos = append(infos, core.FileInfo{Path: f, Kind: string(c.Kind)})
+		}
 	}
+	return infos
+}
 
-	// Commit the staged changes
-	commitCmd := exec.Command("git", "commit", "-m", c.Title, "-m", c.Message)
-	commitOutput, commitErr := commitCmd.CombinedOutput()
-	if commitErr != nil {
-		return fmt.Errorf("failed to apply commit: %v\nOutput: %s", commitErr, string(commitOutput))
+// apiChanges summarizes the changed declarations of the text files a
+// summarizer understands.
+func apiChanges(files []string, changes map[string]git.FileChange, staged bool) string {
+	var sources []core.SourceChange
+	for _, f := range files {
+		if c, ok := changes[f]; (ok && c.Kind != git.KindText) || !core.HasSummarizer(f) {
+			continue
+		}
+		before, after := git.FileVersions(f, staged)
+		sources = append(sources, core.SourceChange{Path: f, Old: before, New: after})
 	}
-	return nil
+	return core.SummarizeChanges(sources)
 }
 
 // ===== AI COMMIT GENERATION
@@ -270,9 +378,14 @@ func Run(cmd *cobra.Command, args []string, c *core.Core) {
 		fmt.Println(cmd.Version)
 		return
 	}
-	status, diffs, err := git.GetGitInfo()
+	scope, _ := cmd.Flags().GetString("scope")
+	status, diffs, err := git.GetScopedGitInfo(scope)
 	if err != nil {
-		if err.Error() == "nothing to commit" {
+		if errors.Is(err, git.ErrNothingToCommit) {
+			if scope != "" {
+				fmt.Printf("No changes to commit in %s.\n", scope)
+				return
+			}
 			fmt.Println("No changes to commit. Make some changes and try again.")
 			return
 		}
@@ -280,39 +393,64 @@ func Run(cmd *cobra.Command, args []string, c *core.Core) {
 		os.Exit(1)
 	}
 
-	var subject string
-	if len(args) > 0 {
-		subject = args[0]
+	generate(cmd, args, c, status, diffs, false)
+}
+
+// generate creates a commit message for the given changes and lets the user
+// decide what to do with it. Staged mode commits only what is already in
+// the index.
+func generate(cmd *cobra.Command, args []string, c *core.Core, status, diffs string, staged bool) {
+	commitOpts, err := commitOptionsFromFlags(cmd, args)
+	if err != nil {
+		log.Error().Err(err).Msg("Invalid commit options")
+		os.Exit(1)
 	}
 
 	forceFlag, _ := cmd.Flags().GetBool("force")
-	prefix, _ := cmd.Flags().GetString("prefix")
 	log.Debug().Msgf("Force: %t", forceFlag)
-	log.Debug().Msgf("Prefix: %s", prefix)
+	log.Debug().Msgf("Prefix: %s", commitOpts.prefix)
 
-	commitMessage, err := generateCommitMessage(c, status, diffs, subject)
+	commitMessage, err := generateCommitMessage(c, status, diffs, commitOpts, staged)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to generate commit message")
 		os.Exit(1)
 	}
 
-	if prefix != "" {
-		commitMessage.Title = prefix + " " + commitMessage.Title
+	target := commitTarget{staged: staged, cleanup: commitOpts.cleanup, gitArgs: commitOpts.gitArgs}
+	if commitOpts.scope != "" && !staged {
+		// Leave changes outside the scope out of the commit
+		target.files, err = git.GetChangedFiles(status)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to get changed files")
+			os.Exit(1)
+		}
 	}
-
 	if forceFlag {
-		handleForcedCommit(commitMessage)
+		handleForcedCommit(commitMessage, target)
 	} else {
-		handleUserResponse(cmd, args, commitMessage, c)
+		handleUserResponse(cmd, args, commitMessage, c, target)
 	}
 }
 
-func handleForcedCommit(commitMessage *Commit) {
-	err := applyCommit(commitMessage)
-	if err != nil {
-		log.Error().Err(err).Msg("Failed to apply commit")
+func handleForcedCommit(commitMessage *Commit, target commitTarget) {
+	printWarnings(commitMessage)
+	if err := target.apply(commitMessage); err != nil {
+		var commitErr *git.CommitError
+		if errors.As(err, &commitErr) {
+			fmt.Fprintln(os.Stderr, describeCommitError(err))
+		} else {
+			log.Error().Err(err).Msg("Failed to apply commit")
+		}
 		os.Exit(1)
 	}
+	recordAction(commitMessage, history.ActionCommitted)
 	fmt.Printf("Commit applied: %s\n", commitMessage.Title)
 	os.Exit(0)
 }
+
+// printWarnings shows the commit's warnings outside the TUI.
+func printWarnings(commit *Commit) {
+	for _, w := range commit.Warnings {
+		fmt.Fprintln(os.Stderr, w)
+	}
+}
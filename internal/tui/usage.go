@@ -0,0 +1,64 @@
+package tui
+
+import (
+	"commi/internal/config"
+	"commi/internal/ledger"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// RunUsage prints the tokens used and their estimated cost per day and
+// provider.
+func RunUsage(cmd *cobra.Command, args []string) {
+	days, _ := cmd.Flags().GetInt("days")
+
+	prices, err := ledger.ParsePrices(config.Load().Prices)
+	if err != nil {
+		log.Error().Err(err).Msg("Invalid COMMI_PRICES")
+		os.Exit(1)
+	}
+	entries, err := ledger.Load()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to read the usage ledger")
+		os.Exit(1)
+	}
+
+	now := time.Now()
+	since := time.Date(now.Year(), now.Month(), now.Day()-days+1, 0, 0, 0, 0, time.Local)
+	totals := ledger.Totals(entries, prices, since)
+	if len(totals) == 0 {
+		fmt.Printf("No usage in the last %d days.\n", days)
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "Date\tProvider\tInput\tCached\tOutput\tCost\t")
+	var sum ledger.Total
+	for _, t := range totals {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%s\t\n", t.Day, t.Provider, t.InputTokens, t.CachedTokens, t.OutputTokens, formatCost(t))
+		sum.InputTokens += t.InputTokens
+		sum.CachedTokens += t.CachedTokens
+		sum.OutputTokens += t.OutputTokens
+		sum.Cost += t.Cost
+		sum.Unpriced = sum.Unpriced || t.Unpriced
+	}
+	fmt.Fprintf(w, "Total\t\t%d\t%d\t%d\t%s\t\n", sum.InputTokens, sum.CachedTokens, sum.OutputTokens, formatCost(sum))
+	w.Flush()
+
+	if sum.Unpriced {
+		fmt.Println("\n* Some models have no price, add them to COMMI_PRICES for a complete estimate.")
+	}
+}
+
+func formatCost(t ledger.Total) string {
+	cost := fmt.Sprintf("$%.4f", t.Cost)
+	if t.Unpriced {
+		cost += "*"
+	}
+	return cost
+}
@@ -3,10 +3,15 @@ package main
 import (
 	"commi/internal/clients/anthropic"
 	"commi/internal/clients/openai"
+	"commi/internal/config"
 	"commi/internal/core"
+	"commi/internal/git"
+	"commi/internal/ledger"
 	"commi/internal/tui"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
@@ -24,21 +29,62 @@ var errorLoggingOnly string
 // ===== ROOT COMMAND
 
 var rootCmd = &cobra.Command{
-	Use:     "commi [subject]",
-	Short:   "Generate and apply AI-powered commit messages",
-	Run:     runCommand,
-	Version: version,
-	Args:    cobra.MaximumNArgs(1),
+	Use:              "commi [subject] [-- git commit options]",
+	Short:            "Generate and apply AI-powered commit messages",
+	PersistentPreRun: setupGit,
+	Run:              runCommand,
+	Version:          version,
+	Args:             subjectArgs,
+}
+
+// subjectArgs accepts an optional subject followed by options passed through
+// to git commit after "--", e.g. commi "CR-22" -- -S --no-verify.
+func subjectArgs(cmd *cobra.Command, args []string) error {
+	if dash := cmd.ArgsLenAtDash(); dash != -1 {
+		args = args[:dash]
+	}
+	return cobra.MaximumNArgs(1)(cmd, args)
 }
 
 func init() {
 	rootCmd.Flags().BoolP("version", "v", false, "Display version information")
-	rootCmd.Flags().BoolP("force", "f", false, "Force commit without showing the menu")
-	rootCmd.Flags().StringP("prefix", "p", "", "Specify a custom commit message prefix")
+	rootCmd.Flags().String("scope", "", "Only commit changes under this path, relative to the repository root, and use it as the commit prefix")
+	rootCmd.PersistentFlags().BoolP("force", "f", false, "Force commit without showing the menu")
+	rootCmd.PersistentFlags().StringP("prefix", "p", "", "Specify a custom commit message prefix")
+	rootCmd.PersistentFlags().StringArray("trailer", nil, "Add a trailer to the commit message, e.g. \"Co-authored-by: Name <email>\" (repeatable)")
+	rootCmd.PersistentFlags().BoolP("signoff", "s", false, "Add a Signed-off-by trailer from git config user.name and user.email")
+	rootCmd.PersistentFlags().StringP("directory", "C", "", "Run as if commi was started in <path>, like git -C")
+	rootCmd.PersistentFlags().Bool("no-cache", false, "Generate a new message even if one is cached for the same changes")
+	rootCmd.PersistentFlags().String("cleanup", "", "How git cleans up the commit message: whitespace (default), verbatim, strip or scissors")
+
+	rootCmd.AddCommand(splitCmd)
+	rootCmd.AddCommand(stageCmd)
+
+	prCmd.Flags().StringP("base", "b", "", "Base branch to compare against (default: COMMI_BASE_BRANCH or the remote default branch)")
+	prCmd.Flags().Bool("json", false, "Print the pull request as JSON")
+	prCmd.Flags().BoolP("copy", "c", false, "Copy the pull request to the clipboard")
+	rootCmd.AddCommand(prCmd)
+
+	changelogCmd.Flags().StringP("release", "r", "Unreleased", "Release name for the changelog section")
+	changelogCmd.Flags().BoolP("write", "w", false, "Prepend the section to the changelog file, merging it into an existing section of the same release")
+	changelogCmd.Flags().String("file", "CHANGELOG.md", "Changelog file to write to")
+	rootCmd.AddCommand(changelogCmd)
+	rootCmd.AddCommand(branchCmd)
+
+	lintCmd.Flags().Bool("fix", false, "Fix what can be fixed in place")
+	rootCmd.AddCommand(lintCmd)
+
+	usageCmd.Flags().Int("days", 30, "Number of days to report")
+	rootCmd.AddCommand(usageCmd)
+	rootCmd.AddCommand(historyCmd)
+
+	promptShowCmd.Flags().String("subject", "", "Subject to render the prompt with")
+	promptCmd.AddCommand(promptShowCmd)
+	rootCmd.AddCommand(promptCmd)
 
-	// Configure zerolog
+	// Configure zerolog, on stderr to keep stdout for the generated content
 	output := zerolog.ConsoleWriter{
-		Out:        os.Stdout,
+		Out:        os.Stderr,
 		TimeFormat: "",
 		NoColor:    false,
 	}
@@ -106,19 +152,198 @@ func getProvider() (core.LLMClient, error) {
 	return providers["OPENAI"], nil
 }
 
-func runCommand(cmd *cobra.Command, args []string) {
+// setupGit selects the git backend used by every command and locates the
+// repository, either around the working directory or at -C.
+func setupGit(cmd *cobra.Command, args []string) {
 	if versionFlag, _ := cmd.Flags().GetBool("version"); versionFlag {
-		fmt.Println(cmd.Version)
 		return
 	}
+	cfg := config.Load()
+	backend := cfg.GitBackend
+	path, _ := cmd.Flags().GetString("directory")
+	repo, err := git.Open(backend, path)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to open git repository")
+	}
+	if backend != "" {
+		log.Debug().Msgf("Using %s git backend", backend)
+	}
+	git.Use(repo)
+	setupPrompts()
+
+	if cfg.MaxDiffLines > 0 {
+		git.Limits.MaxLines = cfg.MaxDiffLines
+	}
+	if cfg.MaxFileSize > 0 {
+		git.Limits.MaxBytes = cfg.MaxFileSize
+	}
 
+	git.DiffOpts = git.DiffOptions{
+		Context:         cfg.DiffContext,
+		FunctionContext: cfg.DiffFunctionContext == "true",
+		Algorithm:       cfg.DiffAlgorithm,
+		Whitespace:      cfg.DiffWhitespace,
+	}
+	if err := git.DiffOpts.Validate(); err != nil {
+		log.Fatal().Err(err).Msg("Invalid diff options")
+	}
+	if cfg.DiffFunctionContext == config.FunctionContextAuto {
+		git.AutoDiffBudget = cfg.DiffBudget
+	}
+}
+
+// setupPrompts looks for prompt overrides in the repository's .commi/prompts,
+// then in the user's config directory.
+func setupPrompts() {
+	var dirs []string
+	if root, err := git.GetRepoRoot(); err == nil {
+		dirs = append(dirs, filepath.Join(root, ".commi", "prompts"))
+	}
+	if dir, err := os.UserConfigDir(); err == nil {
+		dirs = append(dirs, filepath.Join(dir, "commi", "prompts"))
+	}
+	core.PromptDirs = dirs
+}
+
+func newCore() *core.Core {
 	provider, err := getProvider()
 	if err != nil {
 		log.Fatal().Err(err).Msg("Failed to initialize LLM provider")
 	}
-
+	cfg := config.Load()
 	c := core.NewCore(provider)
-	tui.Run(cmd, args, c)
+	if err := c.SetOutputFormat(cfg.OutputFormat); err != nil {
+		log.Fatal().Err(err).Msg("Invalid output format")
+	}
+	if cfg.CacheTTL > 0 {
+		if dir, err := os.UserCacheDir(); err == nil {
+			c.SetCache(core.NewFileCache(filepath.Join(dir, "commi"), cfg.CacheTTL))
+		}
+	}
+	c.OnUsage(func(u core.Usage) {
+		if err := ledger.Record(u); err != nil {
+			log.Debug().Err(err).Msg("Failed to record usage")
+		}
+	})
+	return c
+}
+
+func runCommand(cmd *cobra.Command, args []string) {
+	if versionFlag, _ := cmd.Flags().GetBool("version"); versionFlag {
+		fmt.Println(cmd.Version)
+		return
+	}
+
+	tui.Run(cmd, args, newCore())
+}
+
+// ===== SPLIT COMMAND
+
+var splitCmd = &cobra.Command{
+	Use:   "split [subject] [-- git commit options]",
+	Short: "Split the working tree changes into multiple logical commits",
+	Args:  subjectArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		tui.RunSplit(cmd, args, newCore())
+	},
+}
+
+// ===== STAGE COMMAND
+
+var stageCmd = &cobra.Command{
+	Use:   "stage [subject] [-- git commit options]",
+	Short: "Interactively select hunks to stage and commit",
+	Args:  subjectArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		tui.RunStage(cmd, args, newCore())
+	},
+}
+
+// ===== PR COMMAND
+
+var prCmd = &cobra.Command{
+	Use:   "pr [subject]",
+	Short: "Generate a pull request title and description for the current branch",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		tui.RunPR(cmd, args, newCore())
+	},
+}
+
+// ===== CHANGELOG COMMAND
+
+var changelogCmd = &cobra.Command{
+	Use:   "changelog <from>..<to>",
+	Short: "Generate Keep a Changelog release notes from a range of commits",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		tui.RunChangelog(cmd, args, newCore())
+	},
+}
+
+// ===== BRANCH COMMAND
+
+var branchCmd = &cobra.Command{
+	Use:   "branch [subject]",
+	Short: "Suggest a branch name from the current changes or a subject and check it out",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		tui.RunBranch(cmd, args, newCore())
+	},
+}
+
+// ===== LINT COMMAND
+
+var lintCmd = &cobra.Command{
+	Use:   "lint <msgfile>",
+	Short: "Check a commit message file, e.g. from a commit-msg hook",
+	Args:  cobra.ExactArgs(1),
+	// Linting doesn't need a repository
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {},
+	Run: func(cmd *cobra.Command, args []string) {
+		tui.RunLint(cmd, args)
+	},
+}
+
+// ===== USAGE COMMAND
+
+var usageCmd = &cobra.Command{
+	Use:   "usage",
+	Short: "Show the tokens used and their estimated cost per day and provider",
+	Args:  cobra.NoArgs,
+	// The ledger isn't tied to a repository
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {},
+	Run: func(cmd *cobra.Command, args []string) {
+		tui.RunUsage(cmd, args)
+	},
+}
+
+// ===== HISTORY COMMAND
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Browse the commit messages generated in this repository and copy or commit one again",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		tui.RunHistory(cmd, args)
+	},
+}
+
+// ===== PROMPT COMMAND
+
+var promptCmd = &cobra.Command{
+	Use:   "prompt",
+	Short: "Inspect the prompts sent to the model",
+}
+
+var promptShowCmd = &cobra.Command{
+	Use:       "show [" + strings.Join(core.PromptNames, "|") + "]",
+	Short:     "Print a prompt rendered for the current repository, the commit prompt by default",
+	Args:      cobra.MatchAll(cobra.MaximumNArgs(1), cobra.OnlyValidArgs),
+	ValidArgs: core.PromptNames,
+	Run: func(cmd *cobra.Command, args []string) {
+		tui.RunPromptShow(cmd, args)
+	},
 }
 
 func main() {